@@ -0,0 +1,126 @@
+// Bnffmt formats BNF grammar files.
+//
+// Usage:
+//
+//     bnffmt [flags] [path ...]
+//
+// Without paths grammar is read from standard input and formatted grammar is
+// written to standard output. Directory paths are walked for .bnf files.
+//
+// Flags:
+//
+//     -w        write result to source file instead of standard output
+//     -l        list files whose formatting differs from bnffmt's
+//     -width n  maximum line width, 80 by default
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/TooManySugar/ll1parser/pkg/bnf/format"
+	"github.com/TooManySugar/ll1parser/pkg/parser"
+)
+
+var (
+	write = flag.Bool("w", false, "write result to source file instead " +
+	                              "of standard output")
+	list = flag.Bool("l", false, "list files whose formatting differs " +
+	                             "from bnffmt's")
+	width = flag.Int("width", format.DefaultWidth, "maximum line width")
+)
+
+// Set to 2 on first error, files are still processed after it
+var exitCode = 0
+
+func report(err error) {
+	fmt.Fprintln(os.Stderr, "bnffmt:", err)
+	exitCode = 2
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: bnffmt [flags] [path ...]")
+	flag.PrintDefaults()
+}
+
+// Formats src of file name, name is file name shown in errors for standard
+// input
+func processFile(name string, src []byte, out io.Writer) {
+	res, err := format.Source(src, format.Options{Width: *width})
+	if err != nil {
+		parser.ErrorPrinter{Filename: name}.Fprint(os.Stderr, err, src)
+		exitCode = 2
+		return
+	}
+
+	changed := !bytes.Equal(src, res)
+	if *list && changed {
+		fmt.Fprintln(out, name)
+	}
+	if *write && changed {
+		info, err := os.Stat(name)
+		if err == nil {
+			err = os.WriteFile(name, res, info.Mode().Perm())
+		}
+		if err != nil {
+			report(err)
+		}
+	}
+	if !*list && !*write {
+		out.Write(res)
+	}
+}
+
+func processPath(path string) {
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry,
+	                                   err error) error {
+		if err != nil {
+			return err
+		}
+		// explicitly given files are formatted whatever their extension
+		if d.IsDir() || p != path && filepath.Ext(p) != ".bnf" {
+			return nil
+		}
+
+		src, err := os.ReadFile(p)
+		if err != nil {
+			report(err)
+			return nil
+		}
+		processFile(p, src, os.Stdout)
+		return nil
+	})
+	if err != nil {
+		report(err)
+	}
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		if *write {
+			fmt.Fprintln(os.Stderr, "bnffmt: can't use -w with standard input")
+			os.Exit(2)
+		}
+
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			report(err)
+		} else {
+			processFile("<standard input>", src, os.Stdout)
+		}
+		os.Exit(exitCode)
+	}
+
+	for _, path := range flag.Args() {
+		processPath(path)
+	}
+	os.Exit(exitCode)
+}
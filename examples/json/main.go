@@ -81,54 +81,62 @@ func main() {
 	jsonParser := parser.NewLL1Parser(*jsonParserTable, *jsonParserTableNames)
 
 	// From this point it can be used to parse JSON data
-	jsonCST, _, err := jsonParser.Parse(jsonText)
+	jsonResult, err := parser.ParseToResult(jsonParser, jsonText)
 	if err != nil {
-		fatalError("can't parse JSON text:", err.Error())
+		ep := parser.ErrorPrinter{Filename: jsonDataFileName}
+		ep.Fprint(os.Stderr, err, []byte(jsonText))
+		os.Exit(1)
 	}
 	fmt.Println("Parsed successfully")
 
 	// Print all key-values of JSON
-	v := NewKeyPrintingVisitor(jsonText, os.Stdout)
+	p := NewKeyPrinter(jsonResult, os.Stdout)
 	fmt.Println("JSON key structure:")
-	cst.Walk(v, jsonCST)
+	err = cst.Traverse(jsonResult.Root, p.Enter, p.Exit)
+	if err != nil {
+		fatalError("can't print JSON keys:", err.Error())
+	}
 }
 
-type keyPrintingVisitor struct {
+type keyPrinter struct {
 	level int
 	f *os.File
-	str *string
+	res *parser.ParseResult
 }
 
-func NewKeyPrintingVisitor(str string, f *os.File) (v keyPrintingVisitor)  {
-	return keyPrintingVisitor{
+func NewKeyPrinter(res *parser.ParseResult, f *os.File) *keyPrinter {
+	return &keyPrinter{
 		level: 0,
 		f: f,
-		str: &str,
+		res: res,
 	}
 }
 
-func (v keyPrintingVisitor) Visit(node cst.Node) (w cst.Visitor) {
-	if node == nil {
-		return nil
-	}
+func (p *keyPrinter) Enter(node cst.Node) error {
+	switch p.res.Name(node) {
+	case "string", "number":
+		return cst.SkipChildren
 
-	nt := node.Type()
-
-	if nt > 10 {
-		return nil
-	}
-
-	if nt == 6 {
+	case "key-value":
 		s := node.Childs()[0].Childs()[1]
-		for i := 0; i < v.level; i++ {
-			fmt.Fprint(v.f, "  ")
+		for i := 0; i < p.level; i++ {
+			fmt.Fprint(p.f, "  ")
 		}
-		fmt.Fprintf(v.f, "%s\n", (*v.str)[s.Pos():s.End()])
+		_, err := fmt.Fprintf(p.f, "%s\n", p.res.Text(s))
+		return err
+
+	case "object", "array":
+		p.level++
 	}
 
-	if nt == 9 || nt == 10 {
-		v.level++
+	return nil
+}
+
+func (p *keyPrinter) Exit(node cst.Node) error {
+	switch p.res.Name(node) {
+	case "object", "array":
+		p.level--
 	}
 
-	return v
+	return nil
 }
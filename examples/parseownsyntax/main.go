@@ -7,6 +7,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/TooManySugar/ll1parser/pkg/bnf"
 	"github.com/TooManySugar/ll1parser/pkg/bnf/fromcst"
@@ -21,7 +22,7 @@ func isNonTerminalsEqual(a bnf.SymbolNonTerminal,
 }
 
 func isTerminalsEqual(a bnf.SymbolTerminal, b bnf.SymbolTerminal) bool {
-	return a.Name == b.Name
+	return a.Name == b.Name && a.CaseInsensitive == b.CaseInsensitive
 }
 
 func isSymbolsEqual(a bnf.Symbol, b bnf.Symbol) bool {
@@ -41,6 +42,15 @@ func isSymbolsEqual(a bnf.Symbol, b bnf.Symbol) bool {
 	case bnf.TypeNothing: {
 		return true
 	}
+	case bnf.TypeGroup: {
+		return isSubstitutionsEqual(a.(bnf.SymbolGroup).Alternatives,
+		                            b.(bnf.SymbolGroup).Alternatives)
+	}
+	case bnf.TypeRepetition: {
+		ar, br := a.(bnf.SymbolRepetition), b.(bnf.SymbolRepetition)
+		return ar.Min == br.Min && ar.Max == br.Max &&
+		       isSymbolsEqual(ar.Symbol, br.Symbol)
+	}
 	default: {}
 	}
 
@@ -99,9 +109,41 @@ func isGrammarsEqual(a bnf.Grammar, b bnf.Grammar) bool {
 	return true
 }
 
+// Returns grammar text with comment and every alternative of rule on it's own
+// tab indented line
+func multiLineString(g bnf.Grammar) string {
+	sb := strings.Builder{}
+	sb.WriteString("; BNF's own syntax\n")
+	for _, rule := range g.Rules {
+		sb.WriteString(rule.Head.String())
+		sb.WriteString(" ::=")
+		for i, sequence := range rule.Tail.Sequences {
+			if i > 0 {
+				sb.WriteString("\n\t|")
+			}
+			sb.WriteString(" ")
+			sb.WriteString(sequence.String())
+		}
+		sb.WriteString(" # end of rule\n")
+	}
+	return sb.String()
+}
+
+func parseGrammar(p parser.LL1Parser, bnfStr string) (*bnf.Grammar, error) {
+	cst, _, err := p.Parse(bnfStr)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse input: %s", err.Error())
+	}
+
+	result, err := fromcst.SelfCSTtoASTBindings().ToAST(cst, bnfStr)
+	if err != nil {
+		return nil, fmt.Errorf("can't build AST from CST: %s", err.Error())
+	}
+	return result, nil
+}
+
 func main() {
 	reference := bnf.SelfGrammar()
-	bnfStr := reference.String()
 
 	parserTable, parserTableNames, err := tablegen.FromGrammar(reference)
 	if err != nil {
@@ -113,23 +155,20 @@ func main() {
 
 	parser := parser.NewLL1Parser(*parserTable, *parserTableNames)
 
-	cst, _, err := parser.Parse(bnfStr)
-	if err != nil {
-		fmt.Fprintln(os.Stderr,
-		             "ERROR: can't parse input:", err.Error())
-		os.Exit(1)
-	}
-
-	result, err := fromcst.SelfCSTtoASTBindings().ToAST(cst, bnfStr)
-	if err != nil {
-		fmt.Fprintln(os.Stderr,
-		             "ERROR: can't build AST from CST:", err.Error())
-		os.Exit(1)
-	}
+	for _, bnfStr := range []string{
+		reference.String(),
+		multiLineString(reference),
+	} {
+		result, err := parseGrammar(parser, bnfStr)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "ERROR:", err.Error())
+			os.Exit(1)
+		}
 
-	if !isGrammarsEqual(reference, *result) {
-		fmt.Fprintln(os.Stderr, "ERROR: final AST not equal to initial")
-		os.Exit(1)
+		if !isGrammarsEqual(reference, *result) {
+			fmt.Fprintln(os.Stderr, "ERROR: final AST not equal to initial")
+			os.Exit(1)
+		}
 	}
 
 	fmt.Println("Resulted AST equal to initial")
@@ -0,0 +1,429 @@
+// Reading of grammars written in ABNF (RFC 5234 with case-sensitive strings
+// of RFC 7405) to bnf.Grammar ready for tablegen.FromGrammar:
+//
+//     g, err := abnf.Parse(src)
+//     ...
+//     table, names, err := tablegen.FromGrammar(*g)
+//
+// ABNF text itself is read with LL1 parser of this library. Rules keep order
+// of the text, so first rule is start rule. Rule names are case-insensitive,
+// references are written with name of rule's definition. Core rules (ALPHA,
+// DIGIT, CRLF, ...) are added after written rules when referenced but not
+// defined.
+//
+// Quoted strings are case-insensitive terminals, %s"..." case-sensitive.
+// Numeric values are bytes, so they must be in range 1 to 255. Ranges become
+// group of single byte alternatives, repetitions and options become
+// bnf.SymbolRepetition. Prose values (<...>) can't be converted and are
+// reported as errors
+package abnf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/TooManySugar/ll1parser/pkg/bnf"
+	"github.com/TooManySugar/ll1parser/pkg/bnf/internal/notation"
+	"github.com/TooManySugar/ll1parser/pkg/cst"
+)
+
+type converter struct {
+	notation.Source
+
+	grammar bnf.Grammar
+	// index of rule in grammar by lower case name
+	index map[string]int
+	// node of first reference of rule by lower case name
+	refs map[string]cst.Node
+}
+
+// Splits items into alternatives by slashes
+func (c *converter) alternatives(items []cst.Node,
+                                 end int) (bnf.Substitution, error) {
+	var res bnf.Substitution
+	var sequence bnf.Sequence
+
+	for _, item := range items {
+		if !c.Is(item, "slash") {
+			symbol, err := c.repetition(item)
+			if err != nil {
+				return res, err
+			}
+			sequence.Symbols = append(sequence.Symbols, symbol)
+			continue
+		}
+
+		if len(sequence.Symbols) == 0 {
+			return res, c.Errorf(item.Pos(), "empty alternative")
+		}
+		res = notation.AppendAlternative(res, sequence)
+		sequence = bnf.Sequence{}
+	}
+
+	if len(sequence.Symbols) == 0 {
+		return res, c.Errorf(end, "empty alternative")
+	}
+	return notation.AppendAlternative(res, sequence), nil
+}
+
+// Parses repeat prefix: n, n*, *m, n*m or *
+func (c *converter) repeat(node cst.Node) (min int, max int, err error) {
+	minStr, maxStr, found := strings.Cut(c.Text(node), "*")
+
+	min, max = 0, -1
+	if minStr != "" {
+		min, err = strconv.Atoi(minStr)
+		if err != nil {
+			return 0, 0, c.Errorf(node.Pos(), "invalid repeat count: %s",
+			                      err)
+		}
+	}
+	if !found {
+		return min, min, nil
+	}
+	if maxStr != "" {
+		max, err = strconv.Atoi(maxStr)
+		if err != nil {
+			return 0, 0, c.Errorf(node.Pos(), "invalid repeat count: %s",
+			                      err)
+		}
+	}
+	if max >= 0 && max < min {
+		return 0, 0, c.Errorf(node.Pos(),
+		                      "repeat maximum is less than minimum")
+	}
+	return min, max, nil
+}
+
+func (c *converter) repetition(node cst.Node) (bnf.Symbol, error) {
+	childs := node.Childs()
+	symbol, err := c.element(childs[len(childs) - 1])
+	if err != nil || len(childs) == 1 {
+		return symbol, err
+	}
+
+	min, max, err := c.repeat(childs[0])
+	if err != nil {
+		return nil, err
+	}
+	return bnf.SymbolRepetition{Symbol: symbol, Min: min, Max: max}, nil
+}
+
+func (c *converter) element(node cst.Node) (bnf.Symbol, error) {
+	child := node.Childs()[0]
+
+	switch {
+	case c.Is(child, "rulename"):
+		name := c.Text(child)
+		key := strings.ToLower(name)
+		if _, ok := c.refs[key]; !ok {
+			c.refs[key] = child
+		}
+		return bnf.SymbolNonTerminal{Name: name}, nil
+
+	case c.Is(child, "group"):
+		return c.group(child)
+
+	case c.Is(child, "quoted"):
+		return c.quoted(child, true), nil
+
+	case c.Is(child, "percent-val"):
+		tail := child.Childs()[1]
+		prefix := tail.Childs()[0]
+		if c.Is(prefix, "case-prefix") {
+			insensitive := strings.ToLower(c.Text(prefix)) == "i"
+			return c.quoted(tail.Childs()[1], insensitive), nil
+		}
+		return c.numVal(tail)
+	}
+
+	return nil, c.Errorf(child.Pos(), "prose value %s is not supported",
+	                     c.Text(child))
+}
+
+func (c *converter) group(node cst.Node) (bnf.Symbol, error) {
+	open := c.Text(node.Childs()[0])
+
+	items := c.Find(node.Childs()[2], "slash", "repetition", "close")
+	closeNode := items[len(items) - 1]
+	items = items[:len(items) - 1]
+
+	close := c.Text(closeNode)
+	if (open == "(") != (close == ")") {
+		return nil, c.Errorf(closeNode.Pos(), "%s closes %s", close, open)
+	}
+
+	alternatives, err := c.alternatives(items, closeNode.Pos())
+	if err != nil {
+		return nil, err
+	}
+
+	group := bnf.SymbolGroup{Alternatives: alternatives}
+	if open == "[" {
+		return bnf.SymbolRepetition{Symbol: group, Min: 0, Max: 1}, nil
+	}
+	return group, nil
+}
+
+func (c *converter) quoted(node cst.Node, insensitive bool) bnf.Symbol {
+	text := c.Text(node)
+	text = text[1:len(text) - 1]
+	if text == "" {
+		return bnf.SymbolNothing{}
+	}
+
+	// case doesn't matter for strings without letters
+	hasLetters := strings.ToLower(text) != strings.ToUpper(text)
+	return bnf.SymbolTerminal{
+		Name: text,
+		CaseInsensitive: insensitive && hasLetters,
+	}
+}
+
+func (c *converter) byteValue(number cst.Node, base int) (byte, error) {
+	text := c.Text(number)
+	v, err := strconv.ParseUint(text, base, 64)
+	if err != nil {
+		return 0, c.Errorf(number.Pos(), "invalid number %s in base %d",
+		                   text, base)
+	}
+	if v == 0 {
+		return 0, c.Errorf(number.Pos(), "zero byte marks end of input " +
+		                                 "and can't be matched")
+	}
+	if v > 0xFF {
+		return 0, c.Errorf(number.Pos(), "value %s is out of byte range",
+		                   text)
+	}
+	return byte(v), nil
+}
+
+// Converts %x41, %x41.42 and %x41-5A forms
+func (c *converter) numVal(tail cst.Node) (bnf.Symbol, error) {
+	base := map[string]int{"x": 16, "d": 10, "b": 2}[
+		strings.ToLower(c.Text(tail.Childs()[0]))]
+	numbers := c.Find(tail, "number")
+
+	values := make([]byte, len(numbers))
+	for i, number := range numbers {
+		v, err := c.byteValue(number, base)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+
+	if !strings.Contains(c.Text(tail), "-") {
+		return bnf.SymbolTerminal{Name: string(values)}, nil
+	}
+
+	first, last := values[0], values[1]
+	if first > last {
+		return nil, c.Errorf(tail.Pos(), "range %s is empty", c.Text(tail))
+	}
+	if first == last {
+		return bnf.SymbolTerminal{Name: string(values[:1])}, nil
+	}
+
+	var alternatives bnf.Substitution
+	for v := int(first); v <= int(last); v++ {
+		alternatives.Sequences = append(alternatives.Sequences, bnf.Sequence{
+			Symbols: []bnf.Symbol{
+				bnf.SymbolTerminal{Name: string([]byte{byte(v)})},
+			},
+		})
+	}
+	return bnf.SymbolGroup{Alternatives: alternatives}, nil
+}
+
+// Adds alternatives of rule started by node and continued on following lines
+func (c *converter) rule(node cst.Node, continuations []cst.Node) error {
+	nameNode := node.Childs()[0]
+	name := c.Text(nameNode)
+	key := strings.ToLower(name)
+
+	definedAs := node.Childs()[1]
+	items := c.Find(definedAs, "slash", "repetition")
+	for _, continuation := range continuations {
+		items = append(items, c.Find(continuation, "slash", "repetition")...)
+	}
+
+	// =/ is = immediately followed by slash
+	childs := definedAs.Childs()
+	eq := childs[len(childs) - 3]
+	incremental := len(items) > 0 && c.Is(items[0], "slash") &&
+	               items[0].Pos() == eq.End()
+	if incremental {
+		items = items[1:]
+	}
+
+	// empty last alternative is reported at line break ending the rule
+	end := node.End() - 1
+	if len(continuations) > 0 {
+		end = continuations[len(continuations) - 1].End() - 1
+	}
+	alternatives, err := c.alternatives(items, end)
+	if err != nil {
+		return err
+	}
+
+	i, defined := c.index[key]
+	switch {
+	case incremental && !defined:
+		return c.Errorf(nameNode.Pos(),
+		                "=/ adds alternatives to undefined rule %s", name)
+
+	case incremental:
+		tail := &c.grammar.Rules[i].Tail
+		tail.Sequences = append(tail.Sequences, alternatives.Sequences...)
+		return nil
+
+	case defined:
+		return c.Errorf(nameNode.Pos(), "rule %s is already defined", name)
+	}
+
+	c.index[key] = len(c.grammar.Rules)
+	c.grammar.Rules = append(c.grammar.Rules, bnf.Rule{
+		Head: bnf.SymbolNonTerminal{Name: name},
+		Tail: alternatives,
+	})
+	return nil
+}
+
+func convert(src string) (*converter, error) {
+	// rule ends with line break
+	if src != "" && !strings.HasSuffix(src, "\n") {
+		src += "\n"
+	}
+
+	tree, _, err := meta.syntax.Parser.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	c := converter{
+		Source: notation.NewSource(meta.syntax, src),
+		index: map[string]int{},
+		refs: map[string]cst.Node{},
+	}
+
+	lines := c.Find(tree, "rule-start", "continuation")
+	for i := 0; i < len(lines); {
+		start := lines[i]
+		if !c.Is(start, "rule-start") {
+			if len(c.Find(start, "repetition")) > 0 {
+				return nil, c.Errorf(start.Pos(),
+				                     "indented line before first rule")
+			}
+			i++
+			continue
+		}
+
+		i++
+		var continuations []cst.Node
+		for i < len(lines) && c.Is(lines[i], "continuation") {
+			continuations = append(continuations, lines[i])
+			i++
+		}
+
+		err := c.rule(start, continuations)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(c.grammar.Rules) == 0 {
+		return nil, fmt.Errorf("no rules defined")
+	}
+	return &c, nil
+}
+
+// Returns grammar of ABNF text without resolving references
+func parseRules(src string) (*bnf.Grammar, error) {
+	c, err := convert(src)
+	if err != nil {
+		return nil, err
+	}
+	return &c.grammar, nil
+}
+
+func (c *converter) resolveSymbol(symbol bnf.Symbol) (bnf.Symbol, error) {
+	switch s := symbol.(type) {
+	case bnf.SymbolNonTerminal:
+		key := strings.ToLower(s.Name)
+		if i, ok := c.index[key]; ok {
+			return c.grammar.Rules[i].Head, nil
+		}
+
+		for _, rule := range meta.core.Rules {
+			if strings.ToLower(rule.Head.Name) == key {
+				c.index[key] = len(c.grammar.Rules)
+				c.grammar.Rules = append(c.grammar.Rules, rule)
+				return rule.Head, nil
+			}
+		}
+		return nil, c.Errorf(c.refs[key].Pos(), "rule %s is not defined",
+		                     s.Name)
+
+	case bnf.SymbolGroup:
+		alternatives, err := c.resolveSubstitution(s.Alternatives)
+		return bnf.SymbolGroup{Alternatives: alternatives}, err
+
+	case bnf.SymbolRepetition:
+		var err error
+		s.Symbol, err = c.resolveSymbol(s.Symbol)
+		return s, err
+	}
+	return symbol, nil
+}
+
+func (c *converter) resolveSubstitution(s bnf.Substitution,
+                                        ) (bnf.Substitution, error) {
+	res := bnf.Substitution{
+		Sequences: make([]bnf.Sequence, len(s.Sequences)),
+	}
+	for i, sequence := range s.Sequences {
+		res.Sequences[i].Symbols = make([]bnf.Symbol, len(sequence.Symbols))
+		for j, symbol := range sequence.Symbols {
+			resolved, err := c.resolveSymbol(symbol)
+			if err != nil {
+				return res, err
+			}
+			res.Sequences[i].Symbols[j] = resolved
+		}
+	}
+	return res, nil
+}
+
+// Replaces references with names of rules' definitions adding referenced
+// core rules, core rules are resolved as they are added
+func (c *converter) resolve() error {
+	for i := 0; i < len(c.grammar.Rules); i++ {
+		tail, err := c.resolveSubstitution(c.grammar.Rules[i].Tail)
+		if err != nil {
+			return err
+		}
+		c.grammar.Rules[i].Tail = tail
+	}
+	return nil
+}
+
+// Returns grammar of ABNF text
+func Parse(src string) (*bnf.Grammar, error) {
+	err := loadMeta()
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := convert(src)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.resolve()
+	if err != nil {
+		return nil, err
+	}
+	return &c.grammar, nil
+}
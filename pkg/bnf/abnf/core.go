@@ -0,0 +1,22 @@
+package abnf
+
+// Core rules of RFC 5234 appendix B.1, added to grammar when referenced but
+// not defined. Zero byte marks end of input for parser, so CHAR, CTL and
+// OCTET don't match it
+const coreRulesText = `ALPHA  = %x41-5A / %x61-7A   ; A-Z / a-z
+BIT    = "0" / "1"
+CHAR   = %x01-7F
+CR     = %x0D
+CRLF   = CR LF
+CTL    = %x01-1F / %x7F
+DIGIT  = %x30-39
+DQUOTE = %x22
+HEXDIG = DIGIT / "A" / "B" / "C" / "D" / "E" / "F"
+HTAB   = %x09
+LF     = %x0A
+LWSP   = *(WSP / CRLF WSP)
+OCTET  = %x01-FF
+SP     = %x20
+VCHAR  = %x21-7E
+WSP    = SP / HTAB
+`
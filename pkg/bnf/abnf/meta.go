@@ -0,0 +1,115 @@
+package abnf
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/TooManySugar/ll1parser/pkg/bnf"
+	"github.com/TooManySugar/ll1parser/pkg/bnf/internal/notation"
+)
+
+// Syntax of ABNF text, relaxed where checks are simpler to do on the tree:
+// brackets are matched, numeric values checked and =/ told from = by
+// converter.
+//
+// Empty alternatives are written last, so table generator reports rules
+// which are not LL(1) instead of preferring longer alternative
+const metaGrammarText = `
+; rule starts at beginning of line and continues on lines starting with
+; whitespace, so every line is parsed separately
+<rulelist>      ::= <line> <rulelist> | ""
+<line>          ::= <rule-start> | <continuation> | <c-nl>
+<rule-start>    ::= <rulename> <defined-as>
+<defined-as>    ::= <wsp> <opt-wsp> "=" <opt-wsp> <items>
+                  | "=" <opt-wsp> <items>
+<continuation>  ::= <wsp> <opt-wsp> <items>
+
+; items of line end with line break
+<items>         ::= <slash> <opt-wsp> <items> | <repetition> <after-rep>
+                  | <c-nl>
+<after-rep>     ::= <slash> <opt-wsp> <items> | <wsp> <opt-wsp> <items>
+                  | <c-nl>
+<slash>         ::= "/"
+
+<repetition>    ::= <repeat> <element> | <element>
+<repeat>        ::= <digits> <opt-star> | "*" <opt-digits>
+<opt-star>      ::= "*" <opt-digits> | ""
+<digits>        ::= <digit> <opt-digits>
+<opt-digits>    ::= <digit> <opt-digits> | ""
+
+<element>       ::= <rulename> | <group> | <quoted> | <percent-val>
+                  | <prose-val>
+<rulename>      ::= <alpha> <rulename-tail>
+<rulename-tail> ::= <rulechar> <rulename-tail> | ""
+<rulechar>      ::= <alpha> | <digit> | "-"
+
+; ( ) and [ ] may span several lines, items inside them end with bracket
+<group>         ::= <open> <ws> <inner>
+<inner>         ::= <close> | <slash> <ws> <inner> | <repetition> <inner-after>
+<inner-after>   ::= <close> | <slash> <ws> <inner> | <ws-char> <ws> <inner>
+<open>          ::= "(" | "["
+<close>         ::= ")" | "]"
+
+<quoted>        ::= '"' <quoted-text> '"'
+<quoted-text>   ::= <quoted-char> <quoted-text> | ""
+<prose-val>     ::= "<" <prose-text> ">"
+<prose-text>    ::= <prose-char> <prose-text> | ""
+
+<percent-val>   ::= "%" <percent-tail>
+<percent-tail>  ::= <case-prefix> <quoted> | <base> <number> <num-more>
+<case-prefix>   ::= "s" | "S" | "i" | "I"
+<base>          ::= "x" | "X" | "d" | "D" | "b" | "B"
+<number>        ::= <hexdig> <number-tail>
+<number-tail>   ::= <hexdig> <number-tail> | ""
+<num-more>      ::= "-" <number> | "." <number> <num-dots> | ""
+<num-dots>      ::= "." <number> <num-dots> | ""
+
+<ws>            ::= <ws-char> <ws> | ""
+<ws-char>       ::= " " | "\t" | <c-nl>
+<opt-wsp>       ::= <wsp> <opt-wsp> | ""
+<wsp>           ::= " " | "\t"
+<c-nl>          ::= <comment> | <newline>
+<comment>       ::= ";" <comment-text> <newline>
+<comment-text>  ::= <comment-char> <comment-text> | ""
+<newline>       ::= "\n" | "\r\n"
+`
+
+// Byte classes of syntax, too long to be written in BNF
+func charClasses() []bnf.Rule {
+	return []bnf.Rule{
+		notation.CharClass("alpha", 'A', 'Z', 'a', 'z'),
+		notation.CharClass("digit", '0', '9'),
+		notation.CharClass("hexdig", '0', '9', 'A', 'F', 'a', 'f'),
+		notation.CharClass("quoted-char", 0x20, 0x21, 0x23, 0x7E),
+		notation.CharClass("prose-char", 0x20, 0x3D, 0x3F, 0x7E),
+		notation.CharClass("comment-char", '\t', '\t', 0x20, 0x7E),
+	}
+}
+
+// ABNF parser with node types of it's grammar and core rules, built once on
+// first use
+var meta struct {
+	once sync.Once
+	err error
+
+	syntax *notation.Syntax
+	core *bnf.Grammar
+}
+
+func loadMeta() error {
+	meta.once.Do(func() {
+		syntax, err := notation.NewSyntax("ABNF", metaGrammarText,
+		                                  charClasses()...)
+		if err != nil {
+			meta.err = err
+			return
+		}
+		meta.syntax = syntax
+
+		meta.core, err = parseRules(coreRulesText)
+		if err != nil {
+			meta.err = fmt.Errorf("can't parse ABNF core rules: %w", err)
+		}
+	})
+	return meta.err
+}
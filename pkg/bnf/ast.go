@@ -1,6 +1,7 @@
 package bnf
 
 import (
+	"fmt"
 	"strings"
 )
 
@@ -9,6 +10,8 @@ const (
 	TypeNonTerminal int = iota
 	TypeTerminal
 	TypeNothing // Nothing
+	TypeGroup
+	TypeRepetition
 )
 
 // Interface used to provide fake inhertance among possible BNF Type
@@ -40,6 +43,8 @@ func (nt SymbolNonTerminal) String() string {
 
 type SymbolTerminal struct {
 	Name string
+	// Match Name ignoring case of ASCII letters
+	CaseInsensitive bool
 }
 
 func (nt SymbolTerminal) Type() int {
@@ -53,11 +58,15 @@ func (t SymbolTerminal) String() string {
 	res = strings.ReplaceAll(res, "\t", "\\t")
 	res = strings.ReplaceAll(res, "\n", "\\n")
 	res = strings.ReplaceAll(res, "\r", "\\r")
+	res = escapeBytes(res)
 
 	if strings.ContainsRune(res, '"') && !strings.ContainsRune(res, '\'') {
 		sb.WriteByte('\'')
 		sb.WriteString(res)
 		sb.WriteByte('\'')
+		if t.CaseInsensitive {
+			sb.WriteByte('i')
+		}
 		return sb.String()
 	}
 
@@ -67,6 +76,23 @@ func (t SymbolTerminal) String() string {
 	sb.WriteString(res)
 
 	sb.WriteByte('"')
+	if t.CaseInsensitive {
+		sb.WriteByte('i')
+	}
+	return sb.String()
+}
+
+
+// Returns s with control and non-ASCII bytes written as \xHH
+func escapeBytes(s string) string {
+	sb := strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < 0x20 || c >= 0x7F {
+			fmt.Fprintf(&sb, "\\x%02X", c)
+		} else {
+			sb.WriteByte(c)
+		}
+	}
 	return sb.String()
 }
 
@@ -83,15 +109,97 @@ func (n SymbolNothing) String() string {
 }
 
 
+// Parenthesized alternatives used as single symbol, i.e. ("a" | <b> "c")
+type SymbolGroup struct {
+	Alternatives Substitution
+}
+
+func (g SymbolGroup) Type() int {
+	return TypeGroup
+}
+
+func (g SymbolGroup) String() string {
+	sb := strings.Builder{}
+	sb.WriteByte('(')
+	sb.WriteString(g.Alternatives.String())
+	sb.WriteByte(')')
+	return sb.String()
+}
+
+
+// Symbol repeated from Min to Max times, Max < 0 means no upper bound.
+// Written in grammar text as symbol followed by * (0 or more),
+// + (1 or more) or ? (0 or 1)
+type SymbolRepetition struct {
+	Symbol Symbol
+	Min int
+	Max int
+}
+
+func (r SymbolRepetition) Type() int {
+	return TypeRepetition
+}
+
+// Other bounds than of *, + and ? are written as {min,max} which is not part
+// of grammar text syntax
+func (r SymbolRepetition) String() string {
+	sb := strings.Builder{}
+
+	if r.Symbol.Type() == TypeRepetition {
+		sb.WriteByte('(')
+		sb.WriteString(r.Symbol.String())
+		sb.WriteByte(')')
+	} else {
+		sb.WriteString(r.Symbol.String())
+	}
+
+	switch {
+	case r.Min == 0 && r.Max < 0:
+		sb.WriteByte('*')
+	case r.Min == 1 && r.Max < 0:
+		sb.WriteByte('+')
+	case r.Min == 0 && r.Max == 1:
+		sb.WriteByte('?')
+	case r.Max < 0:
+		fmt.Fprintf(&sb, "{%d,}", r.Min)
+	default:
+		fmt.Fprintf(&sb, "{%d,%d}", r.Min, r.Max)
+	}
+	return sb.String()
+}
+
+
 type Sequence struct {
 	Symbols []Symbol
 }
 
+func (s Sequence) String() string {
+	sb := strings.Builder{}
+	for i, symbol := range s.Symbols {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(symbol.String())
+	}
+	return sb.String()
+}
+
 
 type Substitution struct {
 	Sequences []Sequence
 }
 
+func (s Substitution) String() string {
+	sb := strings.Builder{}
+	for i, sequence := range s.Sequences {
+		if i > 0 {
+			sb.WriteString(" | ")
+		}
+		sb.WriteString(sequence.String())
+	}
+	return sb.String()
+}
+
 
 type Rule struct {
 	Head SymbolNonTerminal
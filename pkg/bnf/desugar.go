@@ -0,0 +1,246 @@
+package bnf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Separator of rule name and number in names of rules generated by Desugar.
+// Rule names in grammar text can't contain it, so generated names never
+// clash with written ones
+const generatedNameSeparator = "."
+
+// Reports whether rule name was generated by Desugar
+func IsGeneratedName(name string) bool {
+	return strings.Contains(name, generatedNameSeparator)
+}
+
+type desugarer struct {
+	// names of all rules, written and generated
+	names map[string]bool
+	// head of rule being desugared, generated names are based on it
+	head string
+	counter int
+
+	generated []Rule
+}
+
+// Adds rule with tail and generated name, returns the name
+func (d *desugarer) newRule(tail Substitution) string {
+	name := ""
+	for name == "" || d.names[name] {
+		d.counter++
+		name = fmt.Sprintf("%s%s%d", d.head, generatedNameSeparator, d.counter)
+	}
+	d.names[name] = true
+
+	d.generated = append(d.generated, Rule{
+		Head: SymbolNonTerminal{Name: name},
+		Tail: tail,
+	})
+	return name
+}
+
+// Reserves generated name for rule which tail depends on the name
+func (d *desugarer) newRecursiveRule(tail func(name string) Substitution,
+                                     ) string {
+	i := len(d.generated)
+	name := d.newRule(Substitution{})
+	// tail can generate rules too, so rule is looked up by index after
+	d.generated[i].Tail = tail(name)
+	return name
+}
+
+func (d *desugarer) substitution(s Substitution) (Substitution, error) {
+	res := Substitution{
+		Sequences: make([]Sequence, len(s.Sequences)),
+	}
+	for i := range s.Sequences {
+		sequence, err := d.sequence(s.Sequences[i])
+		if err != nil {
+			return res, err
+		}
+		res.Sequences[i] = sequence
+	}
+	return res, nil
+}
+
+func (d *desugarer) sequence(s Sequence) (Sequence, error) {
+	var res Sequence
+	for _, symbol := range s.Symbols {
+		symbols, err := d.symbol(symbol)
+		if err != nil {
+			return res, err
+		}
+		res.Symbols = append(res.Symbols, symbols...)
+	}
+	return withoutNothing(res), nil
+}
+
+// Nothing can only be the single symbol in sequence. Removes nothing from
+// sequence with other symbols and makes empty sequence nothing
+func withoutNothing(s Sequence) Sequence {
+	if len(s.Symbols) == 1 {
+		return s
+	}
+
+	res := Sequence{Symbols: make([]Symbol, 0, len(s.Symbols))}
+	for _, symbol := range s.Symbols {
+		if symbol.Type() != TypeNothing {
+			res.Symbols = append(res.Symbols, symbol)
+		}
+	}
+	if len(res.Symbols) == 0 {
+		res.Symbols = append(res.Symbols, SymbolNothing{})
+	}
+	return res
+}
+
+// Appends symbol to every alternative of s
+func appendToAlternatives(s Substitution, symbol Symbol) Substitution {
+	res := Substitution{
+		Sequences: make([]Sequence, len(s.Sequences)),
+	}
+	for i, sequence := range s.Sequences {
+		symbols := make([]Symbol, 0, len(sequence.Symbols) + 1)
+		symbols = append(symbols, sequence.Symbols...)
+		symbols = append(symbols, symbol)
+		res.Sequences[i] = withoutNothing(Sequence{Symbols: symbols})
+	}
+	return res
+}
+
+// Returns alternatives with "" added as first one
+func optional(s Substitution) Substitution {
+	return Substitution{
+		Sequences: append([]Sequence{{Symbols: []Symbol{SymbolNothing{}}}},
+		                  s.Sequences...),
+	}
+}
+
+// Returns sequence matching single occurrence of alternatives
+func (d *desugarer) once(alternatives Substitution) []Symbol {
+	if len(alternatives.Sequences) == 1 {
+		return alternatives.Sequences[0].Symbols
+	}
+	return []Symbol{SymbolNonTerminal{Name: d.newRule(alternatives)}}
+}
+
+// Returns symbols matching repetition:
+//
+//     x{2,} -> x x <r.1>    <r.1> ::= "" | x <r.1>
+//     x{0,2} -> <r.1>       <r.1> ::= "" | x <r.2>    <r.2> ::= "" | x
+func (d *desugarer) repetition(r SymbolRepetition) ([]Symbol, error) {
+	if r.Min < 0 || (r.Max >= 0 && r.Max < r.Min) {
+		return nil, fmt.Errorf("invalid repetition bounds in `%s`", r)
+	}
+
+	var alternatives Substitution
+	var err error
+	if group, ok := r.Symbol.(SymbolGroup); ok {
+		alternatives, err = d.substitution(group.Alternatives)
+	} else {
+		var symbols []Symbol
+		symbols, err = d.symbol(r.Symbol)
+		alternatives.Sequences = []Sequence{{Symbols: symbols}}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var res []Symbol
+	if r.Min > 0 {
+		once := d.once(alternatives)
+		for i := 0; i < r.Min; i++ {
+			res = append(res, once...)
+		}
+	}
+
+	if r.Max < 0 {
+		star := d.newRecursiveRule(func(name string) Substitution {
+			return optional(appendToAlternatives(alternatives,
+			                    SymbolNonTerminal{Name: name}))
+		})
+		res = append(res, SymbolNonTerminal{Name: star})
+	} else if r.Max > r.Min {
+		res = append(res, SymbolNonTerminal{
+			Name: d.optionalChain(alternatives, r.Max - r.Min),
+		})
+	}
+
+	if len(res) == 0 {
+		res = append(res, SymbolNothing{})
+	}
+	return res, nil
+}
+
+// Generates rule matching from 0 to count occurrences of alternatives
+func (d *desugarer) optionalChain(alternatives Substitution,
+                                  count int) string {
+	if count == 1 {
+		return d.newRule(optional(alternatives))
+	}
+
+	return d.newRecursiveRule(func(string) Substitution {
+		next := d.optionalChain(alternatives, count - 1)
+		return optional(appendToAlternatives(alternatives,
+		                    SymbolNonTerminal{Name: next}))
+	})
+}
+
+func (d *desugarer) symbol(symbol Symbol) ([]Symbol, error) {
+	switch s := symbol.(type) {
+	case SymbolGroup:
+		alternatives, err := d.substitution(s.Alternatives)
+		if err != nil {
+			return nil, err
+		}
+		return d.once(alternatives), nil
+
+	case SymbolRepetition:
+		return d.repetition(s)
+	}
+	return []Symbol{symbol}, nil
+}
+
+// Returns grammar with groups and repetitions replaced by generated rules
+// which can be used by table generator. Generated rules are named after
+// rule they were generated for with number, i.e. <list.1>, and are placed
+// after written rules, so written rule keeps it's index.
+//
+// Repetitions are right recursive like hand written tails:
+//
+//     <list> ::= <item> ("," <item>)*
+//
+// becomes
+//
+//     <list>   ::= <item> <list.1>
+//     <list.1> ::= "" | "," <item> <list.1>
+func Desugar(g Grammar) (Grammar, error) {
+	d := desugarer{
+		names: make(map[string]bool, len(g.Rules)),
+	}
+	for _, rule := range g.Rules {
+		d.names[rule.Head.Name] = true
+	}
+
+	res := Grammar{
+		Rules: make([]Rule, len(g.Rules)),
+	}
+	for i, rule := range g.Rules {
+		d.head = rule.Head.Name
+		d.counter = 0
+
+		tail, err := d.substitution(rule.Tail)
+		if err != nil {
+			return res, fmt.Errorf("rule <%s>: %w", rule.Head.Name, err)
+		}
+		res.Rules[i] = Rule{
+			Head: rule.Head,
+			Tail: tail,
+		}
+	}
+
+	res.Rules = append(res.Rules, d.generated...)
+	return res, nil
+}
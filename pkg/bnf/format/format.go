@@ -0,0 +1,352 @@
+// Canonical formatting of BNF grammars in style of bnf.SelfGrammar's doc:
+//
+//     <syntax>     ::= <opt-whitespace> <rule-head> <items>
+//     <item>       ::= "|" <opt-whitespace> | <factor> <opt-whitespace>
+//                    | <reference> <ref-or-head>
+//
+// Heads are padded to the longest head, so "::=" of all rules are aligned.
+// Alternatives are packed on lines up to Options.Width, following lines of
+// rule start with "|" under "=" of "::=". Alternative longer than the width
+// gets it's own line and is never split. Repetitions with bounds, which come
+// from other syntaxes, are expanded to optional and repeated symbols.
+//
+// Source formats BNF text keeping comments and single blank lines between
+// rules. Import directives of loader package at the start of text are kept
+// as is. Comment on line of rule's last alternative stays there, comments
+// inside of rule are moved above it. Formatting is idempotent and formatted
+// text describes the same language as original one
+package format
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/TooManySugar/ll1parser/pkg/bnf"
+	"github.com/TooManySugar/ll1parser/pkg/bnf/fromcst"
+	"github.com/TooManySugar/ll1parser/pkg/bnf/internal/imports"
+	"github.com/TooManySugar/ll1parser/pkg/bnf/tablegen"
+	"github.com/TooManySugar/ll1parser/pkg/cst"
+	"github.com/TooManySugar/ll1parser/pkg/parser"
+)
+
+const DefaultWidth = 80
+
+type Options struct {
+	// Maximum length of line in bytes, DefaultWidth if not positive
+	Width int
+}
+
+type formatter struct {
+	width int
+	// length of longest head
+	headWidth int
+}
+
+func newFormatter(g bnf.Grammar, opts Options) formatter {
+	f := formatter{width: opts.Width}
+	if f.width <= 0 {
+		f.width = DefaultWidth
+	}
+	for _, rule := range g.Rules {
+		if l := len(rule.Head.String()); l > f.headWidth {
+			f.headWidth = l
+		}
+	}
+	return f
+}
+
+// Returns symbol repeated as r with bounds which BNF syntax can't express
+// expanded, so x{2,4} is x x (x x?)? and x{2,} is x x+. Repetition with
+// minimum greater than maximum is kept as is
+func repetition(r bnf.SymbolRepetition) []bnf.Symbol {
+	x := group(expand(r.Symbol))
+	switch {
+	case r.Max >= 0 && r.Min > r.Max,
+	     r.Max < 0 && r.Min <= 1,
+	     r.Min == 0 && r.Max == 1:
+		return []bnf.Symbol{bnf.SymbolRepetition{Symbol: x, Min: r.Min,
+		                                          Max: r.Max}}
+	}
+
+	var res []bnf.Symbol
+	if r.Max < 0 {
+		for i := 1; i < r.Min; i++ {
+			res = append(res, x)
+		}
+		return append(res, bnf.SymbolRepetition{Symbol: x, Min: 1, Max: -1})
+	}
+
+	for i := 0; i < r.Min; i++ {
+		res = append(res, x)
+	}
+	// optional symbols are nested, so sequence stays LL(1)
+	var tail []bnf.Symbol
+	for i := r.Min; i < r.Max; i++ {
+		tail = []bnf.Symbol{bnf.SymbolRepetition{
+			Symbol: group(append([]bnf.Symbol{x}, tail...)), Min: 0, Max: 1,
+		}}
+	}
+	return append(res, tail...)
+}
+
+// Returns symbols as single symbol, grouping them if there are several
+func group(symbols []bnf.Symbol) bnf.Symbol {
+	if len(symbols) == 1 {
+		return symbols[0]
+	}
+	return bnf.SymbolGroup{Alternatives: bnf.Substitution{
+		Sequences: []bnf.Sequence{sequenceOf(symbols)},
+	}}
+}
+
+// Returns symbols of s with bounded repetitions expanded
+func expand(s bnf.Symbol) []bnf.Symbol {
+	switch s := s.(type) {
+	case bnf.SymbolRepetition:
+		return repetition(s)
+	case bnf.SymbolGroup:
+		var res bnf.Substitution
+		for _, seq := range s.Alternatives.Sequences {
+			res.Sequences = append(res.Sequences, expandSequence(seq))
+		}
+		return []bnf.Symbol{bnf.SymbolGroup{Alternatives: res}}
+	}
+	return []bnf.Symbol{s}
+}
+
+// Returns sequence of symbols, "" if there are none
+func sequenceOf(symbols []bnf.Symbol) bnf.Sequence {
+	if len(symbols) == 0 {
+		return bnf.Sequence{Symbols: []bnf.Symbol{bnf.SymbolNothing{}}}
+	}
+	return bnf.Sequence{Symbols: symbols}
+}
+
+func expandSequence(s bnf.Sequence) bnf.Sequence {
+	var res []bnf.Symbol
+	for _, symbol := range s.Symbols {
+		res = append(res, expand(symbol)...)
+	}
+	return sequenceOf(res)
+}
+
+// Returns lines of rule
+func (f formatter) rule(r bnf.Rule) []string {
+	head := r.Head.String()
+	prefix := head + strings.Repeat(" ", f.headWidth - len(head)) + " ::= "
+	// "|" is under "=" of "::="
+	continuation := strings.Repeat(" ", len(prefix) - 2) + "| "
+
+	var res []string
+	line := prefix
+	for i, sequence := range r.Tail.Sequences {
+		alternative := expandSequence(sequence).String()
+		switch {
+		case i == 0:
+			line += alternative
+		case len(line) + len(" | ") + len(alternative) <= f.width:
+			line += " | " + alternative
+		default:
+			res = append(res, line)
+			line = continuation + alternative
+		}
+	}
+	return append(res, line)
+}
+
+// Returns text of grammar formatted with options
+func Grammar(g bnf.Grammar, opts Options) string {
+	f := newFormatter(g, opts)
+
+	var lines []string
+	for _, rule := range g.Rules {
+		lines = append(lines, f.rule(rule)...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// BNF parser with node type of comment, built once on first use
+var self struct {
+	once sync.Once
+	err error
+
+	parser parser.LL1Parser
+	commentType int
+}
+
+func loadSelf() error {
+	self.once.Do(func() {
+		table, names, err := tablegen.FromGrammar(bnf.SelfGrammar())
+		if err != nil {
+			self.err = err
+			return
+		}
+
+		self.parser = parser.NewLL1Parser(*table, *names)
+		for t, name := range *names {
+			if name == "comment" {
+				self.commentType = t
+			}
+		}
+	})
+	return self.err
+}
+
+type sourceWriter struct {
+	src string
+	sb strings.Builder
+	// end of last written element in source, negative before first one
+	prevEnd int
+}
+
+// Reports whether source has blank line between end of previous element and
+// pos
+func (w *sourceWriter) blankBefore(pos int) bool {
+	return w.prevEnd >= 0 &&
+	       strings.Count(w.src[w.prevEnd:pos], "\n") >= 2
+}
+
+func (w *sourceWriter) write(blank bool, lines ...string) {
+	if blank {
+		w.sb.WriteByte('\n')
+	}
+	for _, line := range lines {
+		w.sb.WriteString(line)
+		w.sb.WriteByte('\n')
+	}
+}
+
+// Returns text of comment without line break
+func (w *sourceWriter) comment(node cst.Node) string {
+	return strings.TrimRight(w.src[node.Pos():node.End()], " \t\r\n")
+}
+
+// Writes comment as separate element
+func (w *sourceWriter) writeComment(node cst.Node) {
+	text := w.comment(node)
+	w.write(w.blankBefore(node.Pos()), text)
+	w.prevEnd = node.Pos() + len(text)
+}
+
+// Returns end of last symbol of rule ending at end, comments are sorted
+// comment nodes
+func (w *sourceWriter) contentEnd(start int, end int,
+                                  comments []cst.Node) int {
+	for end > start {
+		switch w.src[end - 1] {
+		case ' ', '\t', '\r', '\n':
+			end--
+			continue
+		}
+
+		inComment := false
+		for _, c := range comments {
+			if c.Pos() < end && end <= c.End() {
+				end = c.Pos()
+				inComment = true
+				break
+			}
+		}
+		if !inComment {
+			break
+		}
+	}
+	return end
+}
+
+// Returns BNF text src formatted with options
+func Source(src []byte, opts Options) ([]byte, error) {
+	err := loadSelf()
+	if err != nil {
+		return nil, err
+	}
+
+	text := string(src)
+	directives, rules, err := imports.Scan(text)
+	if err != nil {
+		return nil, err
+	}
+
+	w := sourceWriter{src: text, prevEnd: -1}
+	// import directives and comments among them are kept as is, up to end
+	// of line of last directive
+	header := 0
+	if len(directives) > 0 {
+		if rules == "" {
+			return []byte(strings.TrimRight(text, " \t\r\n") + "\n"), nil
+		}
+
+		header = directives[len(directives) - 1].End
+		if i := strings.IndexByte(text[header:], '\n'); i >= 0 {
+			header += i
+		} else {
+			header = len(text)
+		}
+		w.write(false, strings.TrimRight(text[:header], " \t\r"))
+		w.prevEnd = header
+	} else {
+		rules = text
+	}
+
+	tree, _, err := self.parser.Parse(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	bindings := fromcst.SelfCSTtoASTBindings()
+	g, err := bindings.ToAST(tree, rules)
+	if err != nil {
+		return nil, err
+	}
+	heads := bindings.RuleHeads(tree)
+
+	var comments []cst.Node
+	cst.Traverse(tree, func(node cst.Node) error {
+		if node.Type() == self.commentType {
+			if node.Pos() >= header {
+				comments = append(comments, node)
+			}
+			return cst.SkipChildren
+		}
+		return nil
+	}, nil)
+
+	f := newFormatter(*g, opts)
+	c := 0
+	for i, rule := range g.Rules {
+		start := heads[i].Pos()
+		end := len(text)
+		if i + 1 < len(heads) {
+			end = heads[i + 1].Pos()
+		}
+
+		for ; c < len(comments) && comments[c].Pos() < start; c++ {
+			w.writeComment(comments[c])
+		}
+
+		blank := w.blankBefore(start)
+		contentEnd := w.contentEnd(start, end, comments[c:])
+
+		// comments inside of rule go above it
+		for ; c < len(comments) && comments[c].Pos() < contentEnd; c++ {
+			w.write(blank, w.comment(comments[c]))
+			blank = false
+		}
+
+		lines := f.rule(rule)
+		w.prevEnd = contentEnd
+		if c < len(comments) && comments[c].Pos() < end &&
+		   !strings.Contains(text[contentEnd:comments[c].Pos()], "\n") {
+			comment := w.comment(comments[c])
+			lines[len(lines) - 1] += " " + comment
+			w.prevEnd = comments[c].Pos() + len(comment)
+			c++
+		}
+		w.write(blank, lines...)
+	}
+
+	for ; c < len(comments); c++ {
+		w.writeComment(comments[c])
+	}
+	return []byte(w.sb.String()), nil
+}
@@ -1,9 +1,9 @@
 package fromcst
 
 import (
-	"errors"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/TooManySugar/ll1parser/pkg/bnf"
@@ -11,33 +11,10 @@ import (
 	"github.com/TooManySugar/ll1parser/pkg/parser"
 )
 
-type flexVisitor struct {
-	isTypeIgnored  func(int) bool
-	isSearchedType func(int) bool
-	fOnNode        func(cst.Node) error
-	err            *error
-}
-
-func (v flexVisitor) Visit(node cst.Node) (w cst.Visitor) {
-	if *v.err != nil {
-		return nil
-	}
-
-	if v.isTypeIgnored(node.Type()) {
-		return nil
-	}
-
-	if v.isSearchedType(node.Type()) {
-		(*v.err) = v.fOnNode(node)
-		return nil
-	}
-
-	return v
-}
-
 // depth-first cst search
 // When meets isSearchedType(Node.type) == true executes fOnNode on node
-// If fOnNode returned error immediately returns with fOnNode's error
+// If fOnNode returned error immediately returns with fOnNode's error,
+// fOnNode may return cst.SkipAll to stop search without error
 // Childs of node on which fOnNode executed are ignored
 // When Traverse complete returns nil
 // Nodes on isTypeIgnored(Node.type) == true are ignored in traverse
@@ -47,16 +24,23 @@ func lrTraverse(root cst.Node,
                 isSearchedType func(int) bool,
                 fOnNode func(cst.Node) error) error {
 
-	var err error = nil
-	var v flexVisitor
-	v.err = &err
-	v.isTypeIgnored = isTypeIgnored
-	v.isSearchedType = isSearchedType
-	v.fOnNode = fOnNode
+	enter := func(node cst.Node) error {
+		if isTypeIgnored(node.Type()) {
+			return cst.SkipChildren
+		}
+
+		if isSearchedType(node.Type()) {
+			err := fOnNode(node)
+			if err != nil {
+				return err
+			}
+			return cst.SkipChildren
+		}
 
-	cst.Walk(v, root)
+		return nil
+	}
 
-	return err
+	return cst.Traverse(root, enter, nil)
 }
 
 func nodeName(node cst.Node, str string) string {
@@ -66,34 +50,69 @@ func nodeName(node cst.Node, str string) string {
 type BNFCSTtoASTBindings struct {
 	IgnoreNodeTypes []int
 
-	// nonterminal containing full singular rule syntax
-	// (i.e. single meaningfull BNF line)
-	RuleType int
-
-	// first accurance of this nonterminal in RuleType will be threated as
-	// head for rule
-	// (i.e. full text inside < > (not including them) to the left from '::=')
+	// first accurance of this nonterminal is head of the first rule
+	// (i.e. full text to the left from first '::=')
 	RuleHeadType int
 
-	// first accurance of this nonterminal in RuleType will be threated as
-	// tail for rule
-	// (i.e. full meaningfull expression to the right from '::=')
-	RuleTailType int
+	// nodes of this type form flat stream of rule tails: symbols,
+	// alternative separators and heads of following rules. Item without
+	// symbol separates alternatives
+	ItemType int
 
-	ExpressionSequencesType int
+	// non empty node of this type inside item makes item's nonterminal head
+	// of new rule
+	ItemDefineType int
 
-	SequencesSymbolType int
+	// first accurance of this nonterminal in ItemType will be threated as
+	// repeated term
+	SymbolTermType int
+
+	// node of this type outside of SymbolTermType in item holds repetition
+	// operator: "*", "+" or "?"
+	SymbolRepeatType int
+
+	// first accurance of this nonterminal in SymbolTermType makes term
+	// a group, items of the node are parsed as it's alternatives
+	SymbolGroupType int
 
 	SymbolTerminalTypes []int
 
 	SymbolNonTerminalType int
 
+	// Non empty node of this type inside symbol marks terminal as case
+	// insensitive
+	SymbolCaseFlagType int
+
 	// Content of this type (single character striung) will be used to replace
-	// with corresponding value from EscapeMapping
+	// with corresponding value from EscapeMapping. Content not in mapping
+	// of form xHH is replaced with byte of hex digits HH
 	EscapeCharacterType int
 
 	// Map character to it's corresponding value
 	EscapeMapping map[string]string
+
+	// Deprecated: use ItemType and SymbolTermType. Bindings which leave
+	// both of them zero are read the old way: every node of this type is
+	// a rule with head of RuleHeadType and tail of RuleTailType
+	RuleType int
+
+	// Deprecated: see RuleType. Tail of rule, it's nodes of
+	// ExpressionSequencesType are alternatives
+	RuleTailType int
+
+	// Deprecated: see RuleType. Alternative of rule tail, it's nodes of
+	// SequencesSymbolType are symbols
+	ExpressionSequencesType int
+
+	// Deprecated: see RuleType. Symbol of alternative, terminal or
+	// nonterminal
+	SequencesSymbolType int
+}
+
+// Reports whether bindings are written for rule per node syntax of
+// RuleType, as bindings made before ItemType was introduced
+func (b BNFCSTtoASTBindings) isLegacy() bool {
+	return b.ItemType == 0 && b.SymbolTermType == 0
 }
 
 // Handiy wrapper around lrTraverse
@@ -122,25 +141,35 @@ func (b BNFCSTtoASTBindings) lrTraverse(root cst.Node,
 
 func (b BNFCSTtoASTBindings) parseEscape(escSeq cst.Node, str string) ([]byte, error) {
 	var res []byte
-	searchComplete := errors.New("headSearchComplete")
+	found := false
 	doOnEscapedChar := func(escChar cst.Node) error {
 		escStr := str[escChar.Pos():escChar.End()]
 		resStr, ok := b.EscapeMapping[escStr]
-		if !ok {
+		switch {
+		case ok:
+			res = []byte(resStr)
+		// \xHH is byte of it's hex digits
+		case len(escStr) == 3 && escStr[0] == 'x':
+			v, err := strconv.ParseUint(escStr[1:], 16, 8)
+			if err != nil {
+				return fmt.Errorf("invalid escape sequence: \\%s", escStr)
+			}
+			res = []byte{byte(v)}
+		default:
 			return fmt.Errorf("unkknown escape character: %s", escStr)
 		}
-		res = []byte(resStr)
-		return searchComplete
+		found = true
+		return cst.SkipAll
 	}
 
 	err := b.lrTraverse(escSeq, b.EscapeCharacterType, doOnEscapedChar)
-	if err == nil {
-		return nil, fmt.Errorf("not found escape character")
+	if err != nil {
+		return nil, err
 	}
-	if err == searchComplete {
-		return res, nil
+	if !found {
+		return nil, fmt.Errorf("not found escape character")
 	}
-	return nil, err
+	return res, nil
 }
 
 // manually construct terminal literal
@@ -170,10 +199,52 @@ func (b BNFCSTtoASTBindings) parseTerminalName(symbol cst.Node, str string) (str
 	return sb.String(), err
 }
 
-func (b BNFCSTtoASTBindings) parseSymbol(symbol cst.Node, str string) (*bnf.Symbol, error) {
+func (b BNFCSTtoASTBindings) isCaseInsensitive(symbol cst.Node) bool {
+	res := false
+	doOnCaseFlag := func(flagNode cst.Node) error {
+		res = res || flagNode.End() > flagNode.Pos()
+		return nil
+	}
+
+	b.lrTraverse(symbol, b.SymbolCaseFlagType, doOnCaseFlag)
+	return res
+}
+
+func (b BNFCSTtoASTBindings) parseGroup(term cst.Node, str string,
+                                        ) (*bnf.Symbol, error) {
 	var res bnf.Symbol
 
-	searchComplete := errors.New("headSearchComplete")
+	doOnGroup := func(groupNode cst.Node) error {
+		alternatives, err := b.parseSubstitution(b.items(groupNode), str)
+		if err != nil {
+			return fmt.Errorf("group `%s`: %w", nodeName(groupNode, str), err)
+		}
+		res = bnf.SymbolGroup{Alternatives: *alternatives}
+		return cst.SkipAll
+	}
+
+	err := b.lrTraverse(term, b.SymbolGroupType, doOnGroup)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, nil
+	}
+	return &res, nil
+}
+
+func (b BNFCSTtoASTBindings) parseTerm(symbol cst.Node, str string) (*bnf.Symbol, error) {
+	group, err := b.parseGroup(symbol, str)
+	if err != nil || group != nil {
+		return group, err
+	}
+	return b.parseName(symbol, str, b.isCaseInsensitive(symbol))
+}
+
+// Returns terminal or nonterminal of symbol
+func (b BNFCSTtoASTBindings) parseName(symbol cst.Node, str string,
+                                       insensitive bool) (*bnf.Symbol, error) {
+	var res bnf.Symbol
 
 	doOnTerminalName := func(termNameNode cst.Node) error {
 		name, err := b.parseTerminalName(termNameNode, str)
@@ -182,29 +253,29 @@ func (b BNFCSTtoASTBindings) parseSymbol(symbol cst.Node, str string) (*bnf.Symb
 		}
 		if len(name) == 0 {
 			res = bnf.SymbolNothing{}
-			return searchComplete
+			return cst.SkipAll
 		}
-		res = bnf.SymbolTerminal{Name: name}
-		return searchComplete
+		res = bnf.SymbolTerminal{Name: name, CaseInsensitive: insensitive}
+		return cst.SkipAll
 	}
 
 	for _, symbolTermType := range b.SymbolTerminalTypes {
 		err := b.lrTraverse(symbol, symbolTermType, doOnTerminalName)
-		if err == searchComplete {
-			return &res, nil
-		}
 		if err != nil {
 			return nil, err
 		}
+		if res != nil {
+			return &res, nil
+		}
 	}
 
 	doOnNonTerminalName := func(nontermNameNode cst.Node) error {
 		res = bnf.SymbolNonTerminal{Name: nodeName(nontermNameNode, str)}
-		return searchComplete
+		return cst.SkipAll
 	}
 
-	err := b.lrTraverse(symbol, b.SymbolNonTerminalType, doOnNonTerminalName)
-	if err == searchComplete {
+	b.lrTraverse(symbol, b.SymbolNonTerminalType, doOnNonTerminalName)
+	if res != nil {
 		return &res, nil
 	}
 
@@ -212,41 +283,214 @@ func (b BNFCSTtoASTBindings) parseSymbol(symbol cst.Node, str string) (*bnf.Symb
 	                       nodeName(symbol, str))
 }
 
-func (b BNFCSTtoASTBindings) parseSequence(sequence cst.Node, str string,
-										   ) (*bnf.Sequence, error) {
-	var res bnf.Sequence
+// Returns text of repetition operator of symbol
+func (b BNFCSTtoASTBindings) repeatOp(symbol cst.Node, str string) string {
+	isNodeIgnored := func(nodeType int) bool {
+		return nodeType == b.SymbolTermType
+	}
+	isSearchedType := func(nodeType int) bool {
+		return nodeType == b.SymbolRepeatType
+	}
 
-	doOnSymbol := func(symbolNode cst.Node) error {
-		symbol, err := b.parseSymbol(symbolNode, str)
+	op := ""
+	doOnRepeatOp := func(opNode cst.Node) error {
+		op = nodeName(opNode, str)
+		return cst.SkipAll
+	}
+
+	lrTraverse(symbol, isNodeIgnored, isSearchedType, doOnRepeatOp)
+	return op
+}
+
+// Returns symbol of item or nil if item has no symbol
+func (b BNFCSTtoASTBindings) parseSymbol(item cst.Node, str string) (*bnf.Symbol, error) {
+	var res *bnf.Symbol
+
+	doOnTerm := func(termNode cst.Node) error {
+		var err error
+		res, err = b.parseTerm(termNode, str)
 		if err != nil {
 			return err
 		}
-		res.Symbols = append(res.Symbols, *symbol)
-		return nil
+		return cst.SkipAll
 	}
-	err := b.lrTraverse(sequence, b.SequencesSymbolType, doOnSymbol)
+
+	err := b.lrTraverse(item, b.SymbolTermType, doOnTerm)
 	if err != nil {
 		return nil, err
 	}
+	if res == nil {
+		name, ok := b.ruleName(item, str)
+		if !ok {
+			return nil, nil
+		}
+		var nonTerminal bnf.Symbol = bnf.SymbolNonTerminal{Name: name}
+		res = &nonTerminal
+	}
 
-	return &res, nil
+	var repetition bnf.Symbol
+	switch b.repeatOp(item, str) {
+	case "*":
+		repetition = bnf.SymbolRepetition{Symbol: *res, Min: 0, Max: -1}
+	case "+":
+		repetition = bnf.SymbolRepetition{Symbol: *res, Min: 1, Max: -1}
+	case "?":
+		repetition = bnf.SymbolRepetition{Symbol: *res, Min: 0, Max: 1}
+	default:
+		return res, nil
+	}
+	return &repetition, nil
+}
+
+// Returns first nonterminal name in node
+func (b BNFCSTtoASTBindings) ruleName(node cst.Node, str string) (string, bool) {
+	name := ""
+	found := false
+	doOnName := func(nameNode cst.Node) error {
+		name = nodeName(nameNode, str)
+		found = true
+		return cst.SkipAll
+	}
+
+	// doOnName never fails
+	b.lrTraverse(node, b.SymbolNonTerminalType, doOnName)
+	return name, found
 }
 
-func (b BNFCSTtoASTBindings) parseSubstitution(expression cst.Node,
+func (b BNFCSTtoASTBindings) isHead(item cst.Node) bool {
+	isNodeIgnored := func(nodeType int) bool {
+		return nodeType == b.SymbolTermType
+	}
+	isSearchedType := func(nodeType int) bool {
+		return nodeType == b.ItemDefineType
+	}
+
+	res := false
+	doOnDefine := func(defineNode cst.Node) error {
+		res = res || defineNode.End() > defineNode.Pos()
+		return nil
+	}
+
+	lrTraverse(item, isNodeIgnored, isSearchedType, doOnDefine)
+	return res
+}
+
+// Returns items of node not including items of nested groups
+func (b BNFCSTtoASTBindings) items(node cst.Node) []cst.Node {
+	var res []cst.Node
+	doOnItem := func(itemNode cst.Node) error {
+		res = append(res, itemNode)
+		return nil
+	}
+
+	b.lrTraverse(node, b.ItemType, doOnItem)
+	return res
+}
+
+// Reports whether node starts at beginning of line
+func startsLine(node cst.Node, str string) bool {
+	return node.Pos() > 0 && str[node.Pos()-1] == '\n'
+}
+
+// Splits items into alternatives by items without symbol
+func (b BNFCSTtoASTBindings) parseSubstitution(items []cst.Node,
                                                str string,
                                                ) (*bnf.Substitution, error) {
 	var res bnf.Substitution
+	var sequence bnf.Sequence
 
-	doOnSequence := func(sequenceNode cst.Node) error {
-		sequence, err := b.parseSequence(sequenceNode, str)
+	for _, item := range items {
+		if b.isHead(item) {
+			return nil, fmt.Errorf("unexpected rule head `%s`",
+			                       nodeName(item, str))
+		}
+		if startsLine(item, str) {
+			return nil, fmt.Errorf("continuation line not indented at `%s`",
+			                       nodeName(item, str))
+		}
+
+		symbol, err := b.parseSymbol(item, str)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		if symbol != nil {
+			sequence.Symbols = append(sequence.Symbols, *symbol)
+			continue
+		}
+
+		if len(sequence.Symbols) == 0 {
+			return nil, fmt.Errorf("empty alternative before `%s`",
+			                       nodeName(item, str))
+		}
+		res.Sequences = append(res.Sequences, sequence)
+		sequence = bnf.Sequence{}
+	}
+
+	if len(sequence.Symbols) == 0 {
+		return nil, fmt.Errorf("empty alternative")
+	}
+	res.Sequences = append(res.Sequences, sequence)
+
+	return &res, nil
+}
+
+func (b BNFCSTtoASTBindings) ToAST(root cst.Node, str string) (*bnf.Grammar, error) {
+
+	sort.Sort(sort.IntSlice(b.IgnoreNodeTypes))
+
+	if b.isLegacy() {
+		return b.legacyToAST(root, str)
+	}
+
+	var res bnf.Grammar
+
+	addRule := func(name string, items []cst.Node) error {
+		substitution, err := b.parseSubstitution(items, str)
+		if err != nil {
+			return fmt.Errorf("rule <%s>: %w", name, err)
 		}
-		res.Sequences = append(res.Sequences, *sequence)
+		res.Rules = append(res.Rules, bnf.Rule{
+			Head: bnf.SymbolNonTerminal{Name: name},
+			Tail: *substitution,
+		})
 		return nil
 	}
 
-	err := b.lrTraverse(expression, b.ExpressionSequencesType, doOnSequence)
+	var head cst.Node
+	doOnHead := func(headNode cst.Node) error {
+		head = headNode
+		return cst.SkipAll
+	}
+
+	// doOnHead never fails
+	b.lrTraverse(root, b.RuleHeadType, doOnHead)
+	if head == nil {
+		return nil, fmt.Errorf("could not find rule head in `%s`",
+		                       nodeName(root, str))
+	}
+	name, ok := b.ruleName(head, str)
+	if !ok {
+		return nil, fmt.Errorf("could not find rule name in `%s`",
+		                       nodeName(head, str))
+	}
+
+	// items following head up to next head are the rule's tail
+	items := b.items(root)
+	start := 0
+	for i, item := range items {
+		if !b.isHead(item) {
+			continue
+		}
+
+		err := addRule(name, items[start:i])
+		if err != nil {
+			return nil, err
+		}
+		name, _ = b.ruleName(item, str)
+		start = i + 1
+	}
+
+	err := addRule(name, items[start:])
 	if err != nil {
 		return nil, err
 	}
@@ -254,59 +498,121 @@ func (b BNFCSTtoASTBindings) parseSubstitution(expression cst.Node,
 	return &res, nil
 }
 
-func (b BNFCSTtoASTBindings) parseRule(rule cst.Node, str string) (*bnf.Rule, error) {
-	var res bnf.Rule
+// Returns nodes of rule heads in order of rules returned by ToAST, so rules
+// can be mapped back to their source
+func (b BNFCSTtoASTBindings) RuleHeads(root cst.Node) []cst.Node {
+	sort.Sort(sort.IntSlice(b.IgnoreNodeTypes))
+
+	if b.isLegacy() {
+		var res []cst.Node
+		b.lrTraverse(root, b.RuleType, func(ruleNode cst.Node) error {
+			res = append(res, ruleNode)
+			return nil
+		})
+		return res
+	}
 
-	// str := nodeName(rule, str)
-	// res.Head.Name = str
+	var head cst.Node
+	doOnHead := func(headNode cst.Node) error {
+		head = headNode
+		return cst.SkipAll
+	}
 
-	searchComplete := errors.New("headSearchComplete")
+	// doOnHead never fails
+	b.lrTraverse(root, b.RuleHeadType, doOnHead)
+	if head == nil {
+		return nil
+	}
 
-	doOnHead := func(ruleNameNode cst.Node) error {
-		res.Head.Name = nodeName(ruleNameNode, str)
-		return searchComplete
+	res := []cst.Node{head}
+	for _, item := range b.items(root) {
+		if b.isHead(item) {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+func (b BNFCSTtoASTBindings) legacySequence(sequence cst.Node, str string,
+                                            ) (*bnf.Sequence, error) {
+	var res bnf.Sequence
+
+	doOnSymbol := func(symbolNode cst.Node) error {
+		symbol, err := b.parseName(symbolNode, str, false)
+		if err != nil {
+			return err
+		}
+		res.Symbols = append(res.Symbols, *symbol)
+		return nil
+	}
+	err := b.lrTraverse(sequence, b.SequencesSymbolType, doOnSymbol)
+	if err != nil {
+		return nil, err
 	}
 
-	err := b.lrTraverse(rule, b.RuleHeadType, doOnHead)
-	if err != searchComplete {
-		// a single way of how lrTravese could return an error is via f's error
-		// so if err != f's error it is nil doOnHead only returns single type
-		// of error
+	return &res, nil
+}
+
+func (b BNFCSTtoASTBindings) legacyRule(rule cst.Node, str string,
+                                        ) (*bnf.Rule, error) {
+	var res bnf.Rule
+
+	name, ok := "", false
+	doOnHead := func(ruleNameNode cst.Node) error {
+		name, ok = nodeName(ruleNameNode, str), true
+		return cst.SkipAll
+	}
 
+	// doOnHead never fails
+	b.lrTraverse(rule, b.RuleHeadType, doOnHead)
+	if !ok {
 		return nil, fmt.Errorf("could not find rule name in `%s`",
 		                       nodeName(rule, str))
 	}
+	res.Head.Name = name
 
-	doOnSubstitution := func(ruleNode cst.Node) error {
-		substitution, err := b.parseSubstitution(ruleNode, str)
+	found := false
+	doOnSequence := func(sequenceNode cst.Node) error {
+		sequence, err := b.legacySequence(sequenceNode, str)
 		if err != nil {
 			return err
 		}
-		res.Tail = *substitution
-		return searchComplete
+		res.Tail.Sequences = append(res.Tail.Sequences, *sequence)
+		return nil
 	}
-
-	err = b.lrTraverse(rule, b.RuleTailType, doOnSubstitution)
-	if err != searchComplete {
-		if err == nil {
-			return nil, fmt.Errorf("could not find rule substitution in `%s`",
-			                       nodeName(rule, str))
+	doOnTail := func(tailNode cst.Node) error {
+		found = true
+		err := b.lrTraverse(tailNode, b.ExpressionSequencesType, doOnSequence)
+		if err != nil {
+			return err
 		}
+		return cst.SkipAll
+	}
 
+	err := b.lrTraverse(rule, b.RuleTailType, doOnTail)
+	if err != nil {
 		return nil, err
 	}
+	if !found {
+		return nil, fmt.Errorf("could not find rule substitution in `%s`",
+		                       nodeName(rule, str))
+	}
 
 	return &res, nil
 }
 
-func (b BNFCSTtoASTBindings) ToAST(root cst.Node, str string) (*bnf.Grammar, error) {
-
-	sort.Sort(sort.IntSlice(b.IgnoreNodeTypes))
+// ToAST of bindings written for rule per node syntax of RuleType
+func (b BNFCSTtoASTBindings) legacyToAST(root cst.Node,
+                                         str string) (*bnf.Grammar, error) {
+	if b.RuleType == 0 && b.RuleTailType == 0 {
+		return nil, fmt.Errorf("bindings have neither ItemType and " +
+		                       "SymbolTermType nor RuleType and RuleTailType")
+	}
 
 	var res bnf.Grammar
 
 	doOnRule := func(ruleNode cst.Node) error {
-		rule, err := b.parseRule(ruleNode, str)
+		rule, err := b.legacyRule(ruleNode, str)
 		if err != nil {
 			return err
 		}
@@ -315,7 +621,6 @@ func (b BNFCSTtoASTBindings) ToAST(root cst.Node, str string) (*bnf.Grammar, err
 	}
 
 	err := b.lrTraverse(root, b.RuleType, doOnRule)
-
 	if err != nil {
 		return nil, err
 	}
@@ -325,15 +630,17 @@ func (b BNFCSTtoASTBindings) ToAST(root cst.Node, str string) (*bnf.Grammar, err
 
 func SelfCSTtoASTBindings() BNFCSTtoASTBindings {
 	return BNFCSTtoASTBindings{
-		IgnoreNodeTypes:         []int{25},
-		RuleType:                4,
-		RuleHeadType:            18,
-		RuleTailType:            5,
-		ExpressionSequencesType: 7,
-		SequencesSymbolType:     9,
-		SymbolTerminalTypes:     []int{11, 12},
-		SymbolNonTerminalType:   18,
-		EscapeCharacterType:     17,
+		IgnoreNodeTypes:       []int{24},
+		RuleHeadType:          3,
+		ItemType:              2,
+		ItemDefineType:        6,
+		SymbolTermType:        9,
+		SymbolRepeatType:      29,
+		SymbolGroupType:       5,
+		SymbolTerminalTypes:   []int{11, 12},
+		SymbolNonTerminalType: 18,
+		SymbolCaseFlagType:    26,
+		EscapeCharacterType:   17,
 		EscapeMapping: map[string]string{
 			`t`: "\t",
 			`n`: "\n",
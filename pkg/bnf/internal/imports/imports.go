@@ -0,0 +1,82 @@
+// Scanning of import directives written at the start of BNF file before it's
+// first rule, shared by loader and formatter:
+//
+//     ; shared lexical rules
+//     import "common.bnf"
+//     import "lex/numbers.bnf"
+package imports
+
+import (
+	"strings"
+
+	"github.com/TooManySugar/ll1parser/pkg/parser"
+)
+
+// Import directive of file
+type Directive struct {
+	Path string
+	// offset of directive in file
+	Offset int
+	// offset after closing quote of path
+	End int
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// Returns import directives of src and src with directives replaced by
+// spaces, so offsets in text are offsets in src. Text is empty if src has no
+// rules. Malformed directive is reported as *parser.SyntaxError
+func Scan(src string) ([]Directive, string, error) {
+	var res []Directive
+	text := []byte(src)
+
+	errorAt := func(offset int, msg string) error {
+		return &parser.SyntaxError{Offset: offset, Msg: msg}
+	}
+
+	for i := 0; i < len(src); {
+		switch {
+		case isSpace(src[i]):
+			i++
+			continue
+
+		case src[i] == ';' || src[i] == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			continue
+
+		case !strings.HasPrefix(src[i:], "import"):
+			return res, string(text), nil
+		}
+
+		start := i
+		i += len("import")
+		for i < len(src) && (src[i] == ' ' || src[i] == '\t') {
+			i++
+		}
+		if i == start + len("import") || i == len(src) || src[i] != '"' {
+			return nil, "", errorAt(i, "expected quoted path after import")
+		}
+
+		end := strings.IndexAny(src[i + 1:], "\"\n")
+		if end < 0 || src[i + 1 + end] != '"' {
+			return nil, "", errorAt(i, "unterminated import path")
+		}
+		end += i + 1
+
+		p := src[i + 1:end]
+		if p == "" {
+			return nil, "", errorAt(i, "empty import path")
+		}
+		res = append(res, Directive{Path: p, Offset: start, End: end + 1})
+
+		for j := start; j <= end; j++ {
+			text[j] = ' '
+		}
+		i = end + 1
+	}
+	return res, "", nil
+}
@@ -0,0 +1,143 @@
+// Common parts of readers of other grammar notations, like ABNF and W3C EBNF.
+// Notation's syntax is written in BNF of this library, text is parsed with
+// LL1 parser built from it and parsed tree is converted to bnf.Grammar
+package notation
+
+import (
+	"fmt"
+
+	"github.com/TooManySugar/ll1parser/pkg/bnf"
+	"github.com/TooManySugar/ll1parser/pkg/bnf/fromcst"
+	"github.com/TooManySugar/ll1parser/pkg/bnf/tablegen"
+	"github.com/TooManySugar/ll1parser/pkg/cst"
+	"github.com/TooManySugar/ll1parser/pkg/parser"
+	"github.com/TooManySugar/ll1parser/pkg/token"
+)
+
+// Returns rule matching any single byte of ranges, ranges are pairs of first
+// and last byte
+func CharClass(name string, ranges ...byte) bnf.Rule {
+	rule := bnf.Rule{Head: bnf.SymbolNonTerminal{Name: name}}
+	for i := 0; i + 1 < len(ranges); i += 2 {
+		for c := int(ranges[i]); c <= int(ranges[i + 1]); c++ {
+			rule.Tail.Sequences = append(rule.Tail.Sequences, bnf.Sequence{
+				Symbols: []bnf.Symbol{
+					bnf.SymbolTerminal{Name: string([]byte{byte(c)})},
+				},
+			})
+		}
+	}
+	return rule
+}
+
+// Parser of notation with node types of it's grammar
+type Syntax struct {
+	Parser parser.LL1Parser
+	// node type of syntax rule name
+	Types map[string]int
+}
+
+func grammar(text string, rules []bnf.Rule) (*bnf.Grammar, error) {
+	table, names, err := tablegen.FromGrammar(bnf.SelfGrammar())
+	if err != nil {
+		return nil, err
+	}
+
+	tree, _, err := parser.NewLL1Parser(*table, *names).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	g, err := fromcst.SelfCSTtoASTBindings().ToAST(tree, text)
+	if err != nil {
+		return nil, err
+	}
+
+	g.Rules = append(g.Rules, rules...)
+	return g, nil
+}
+
+// Returns syntax of notation called name, which grammar is BNF text followed
+// by rules
+func NewSyntax(name string, text string, rules ...bnf.Rule) (*Syntax, error) {
+	g, err := grammar(text, rules)
+	if err != nil {
+		return nil, fmt.Errorf("can't build %s grammar: %w", name, err)
+	}
+
+	table, names, err := tablegen.FromGrammar(*g)
+	if err != nil {
+		return nil, fmt.Errorf("can't build %s parser table: %w", name, err)
+	}
+
+	s := &Syntax{
+		Parser: parser.NewLL1Parser(*table, *names),
+		Types: make(map[string]int, len(*names)),
+	}
+	for t, name := range *names {
+		s.Types[name] = t
+	}
+	return s, nil
+}
+
+// Text of notation being converted
+type Source struct {
+	Src string
+	File *token.File
+	Syntax *Syntax
+}
+
+func NewSource(syntax *Syntax, src string) Source {
+	return Source{
+		Src: src,
+		File: token.NewFileSet().AddFile("", []byte(src)),
+		Syntax: syntax,
+	}
+}
+
+// Returns error at offset pos of source
+func (s *Source) Errorf(pos int, format string, a ...any) error {
+	return fmt.Errorf("%s: %s", s.File.Position(pos),
+	                  fmt.Sprintf(format, a...))
+}
+
+func (s *Source) Text(node cst.Node) string {
+	return s.Src[node.Pos():node.End()]
+}
+
+func (s *Source) Is(node cst.Node, name string) bool {
+	return node.Type() == s.Syntax.Types[name]
+}
+
+// Returns nodes of types named names in tree of root in order. Nodes inside
+// found nodes are not searched
+func (s *Source) Find(root cst.Node, names ...string) []cst.Node {
+	types := make(map[int]bool, len(names))
+	for _, name := range names {
+		types[s.Syntax.Types[name]] = true
+	}
+
+	var res []cst.Node
+	cst.Traverse(root, func(node cst.Node) error {
+		if types[node.Type()] {
+			res = append(res, node)
+			return cst.SkipChildren
+		}
+		return nil
+	}, nil)
+	return res
+}
+
+// Appends sequence to alternatives, sequence of single group, like range or
+// character class, adds group's alternatives
+func AppendAlternative(s bnf.Substitution,
+                       sequence bnf.Sequence) bnf.Substitution {
+	if len(sequence.Symbols) == 1 {
+		if group, ok := sequence.Symbols[0].(bnf.SymbolGroup); ok {
+			s.Sequences = append(s.Sequences, group.Alternatives.Sequences...)
+			return s
+		}
+	}
+	s.Sequences = append(s.Sequences, sequence)
+	return s
+}
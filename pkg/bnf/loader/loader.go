@@ -0,0 +1,186 @@
+// Loading of BNF grammars split into several files. File may start with
+// imports of other files, written before it's first rule:
+//
+//     ; shared lexical rules
+//     import "common.bnf"
+//     import "lex/numbers.bnf"
+//
+//     <list> ::= <number> ("," <number>)*
+//
+// Import paths are slash separated and relative to directory of importing
+// file. Files are read from fs.FS, so grammars embedded with go:embed can be
+// loaded as well as files of a directory:
+//
+//     //go:embed grammar
+//     var files embed.FS
+//     ...
+//     g, err := loader.Load(files, "grammar/main.bnf")
+//     ...
+//     table, names, err := tablegen.FromGrammar(g.Grammar)
+//
+// Rules of all files are merged into one grammar. Rules of loaded file go
+// first, so it's first rule is start rule, followed by rules of imports in
+// order of import. File imported several times is read once, import cycles
+// and rules defined more than once are reported as errors
+package loader
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/TooManySugar/ll1parser/pkg/bnf"
+	"github.com/TooManySugar/ll1parser/pkg/bnf/fromcst"
+	"github.com/TooManySugar/ll1parser/pkg/bnf/internal/imports"
+	"github.com/TooManySugar/ll1parser/pkg/bnf/tablegen"
+	"github.com/TooManySugar/ll1parser/pkg/parser"
+	"github.com/TooManySugar/ll1parser/pkg/token"
+)
+
+// Grammar merged from file and it's imports
+type Grammar struct {
+	bnf.Grammar
+	// read files, positions of Pos are global positions of this set
+	Files *token.FileSet
+	// position of head of each rule
+	Pos []int
+}
+
+// Returns file, line and column of head of i-th rule
+func (g *Grammar) Position(i int) token.Position {
+	return g.Files.Position(g.Pos[i])
+}
+
+type loader struct {
+	fsys fs.FS
+	parser parser.LL1Parser
+	bindings fromcst.BNFCSTtoASTBindings
+
+	res Grammar
+	// names of files being loaded, importing file first
+	stack []string
+	loaded map[string]bool
+	// index of rule in result by name
+	defined map[string]int
+}
+
+// Returns error at global position pos, position is omitted if it is
+// negative
+func (l *loader) errorf(pos int, format string, a ...any) error {
+	if pos < 0 {
+		return fmt.Errorf(format, a...)
+	}
+	a = append([]any{l.res.Files.Position(pos)}, a...)
+	return fmt.Errorf("%s: " + format, a...)
+}
+
+// Returns error of file, syntax error is positioned at it's offset
+func (l *loader) fileError(file *token.File, err error) error {
+	var se *parser.SyntaxError
+	if errors.As(err, &se) {
+		return l.errorf(file.Pos(se.Offset), "%w", err)
+	}
+	return fmt.Errorf("%s: %w", file.Name(), err)
+}
+
+// Adds rules of file to result
+func (l *loader) addRules(file *token.File, text string) error {
+	tree, _, err := l.parser.Parse(text)
+	if err != nil {
+		return l.fileError(file, err)
+	}
+
+	g, err := l.bindings.ToAST(tree, text)
+	if err != nil {
+		return fmt.Errorf("%s: %w", file.Name(), err)
+	}
+
+	heads := l.bindings.RuleHeads(tree)
+	for i, rule := range g.Rules {
+		pos := file.Pos(heads[i].Pos())
+		if j, ok := l.defined[rule.Head.Name]; ok {
+			return l.errorf(pos, "rule <%s> is already defined at %s",
+			                rule.Head.Name, l.res.Position(j))
+		}
+
+		l.defined[rule.Head.Name] = len(l.res.Rules)
+		l.res.Rules = append(l.res.Rules, rule)
+		l.res.Pos = append(l.res.Pos, pos)
+	}
+	return nil
+}
+
+// Loads file name imported at global position from, from is negative for
+// loaded file
+func (l *loader) load(name string, from int) error {
+	for i, loading := range l.stack {
+		if loading == name {
+			return l.errorf(from, "import cycle: %s -> %s",
+			                strings.Join(l.stack[i:], " -> "), name)
+		}
+	}
+	if l.loaded[name] {
+		return nil
+	}
+
+	src, err := fs.ReadFile(l.fsys, name)
+	if err != nil {
+		return l.errorf(from, "%w", err)
+	}
+	file := l.res.Files.AddFile(name, src)
+
+	directives, text, err := imports.Scan(string(src))
+	if err != nil {
+		return l.fileError(file, err)
+	}
+
+	if text != "" {
+		err = l.addRules(file, text)
+		if err != nil {
+			return err
+		}
+	}
+
+	l.stack = append(l.stack, name)
+	for _, imp := range directives {
+		pos := file.Pos(imp.Offset)
+		p := path.Join(path.Dir(name), imp.Path)
+		if !fs.ValidPath(p) {
+			return l.errorf(pos, "invalid import path %q", imp.Path)
+		}
+
+		err := l.load(p, pos)
+		if err != nil {
+			return err
+		}
+	}
+	l.stack = l.stack[:len(l.stack) - 1]
+
+	l.loaded[name] = true
+	return nil
+}
+
+// Returns grammar of file name of fsys merged with it's imports
+func Load(fsys fs.FS, name string) (*Grammar, error) {
+	table, names, err := tablegen.FromGrammar(bnf.SelfGrammar())
+	if err != nil {
+		return nil, err
+	}
+
+	l := loader{
+		fsys: fsys,
+		parser: parser.NewLL1Parser(*table, *names),
+		bindings: fromcst.SelfCSTtoASTBindings(),
+		res: Grammar{Files: token.NewFileSet()},
+		loaded: map[string]bool{},
+		defined: map[string]int{},
+	}
+
+	err = l.load(name, -1)
+	if err != nil {
+		return nil, err
+	}
+	return &l.res, nil
+}
@@ -8,22 +8,37 @@ package bnf
 //
 // This AST in BNF:
 //
-// Note: \ used to express line continuation on the next line,
-//       Grammar not support multiline rules as it seen
+// Note: rule continues on following lines until next rule head, so long
+//       rules can be split on several lines. Continuation lines must start
+//       with space or tab
 //
-//     <syntax>          ::= <opt-whitespace> <content> <more-lines>
-//     <more-lines>      ::= "" | <EOL> <line> <more-lines>
-//     <line>            ::= <opt-whitespace> <opt-content>
+// Note: whitespace is spaces, tabs, line breaks and comments. Comment starts
+//       with ; or # and ends with line break or end of text
 //
-//     <opt-content>     ::= <content> | ""
-//     <content>         ::= "<" <rule-name> ">" <opt-whitespace> "::=" \
-//                           <opt-whitespace> <expression>
+// Note: <other-char> is any byte except printable ASCII, "\t", "\n", "\r"
+//       and zero byte, so comments may contain UTF-8 text. In literals such
+//       bytes are written with escape sequence \xHH
 //
-//     <expression>      ::= <list> <expression-tail>
-//     <expression-tail> ::= "" | "|" <opt-whitespace> <list> <expression-tail>
-//     <list>            ::= <term> <opt-whitespace> <list-tail>
-//     <list-tail>       ::= "" | <term> <opt-whitespace> <list-tail>
-//     <term>            ::= <literal> | "<" <rule-name> ">"
+// Note: literal followed by i (i.e. "select"i) matches text case-insensitively
+//
+// Note: term followed by * matches it 0 or more times, by + 1 or more times,
+//       by ? 0 or 1 time. Alternatives can be grouped with ( ). Grammar
+//       using them is desugared to plain rules by Desugar
+//
+//     ; Rule head is nonterminal followed by "::=", so tail of rule is
+//     ; flat list of items which ends on next rule head
+//     <syntax>          ::= <opt-whitespace> <rule-head> <items>
+//     <items>           ::= "" | <item> <items>
+//     <item>            ::= "|" <opt-whitespace> | <factor> <opt-whitespace>
+//                         | <reference> <ref-or-head>
+//     <rule-head>       ::= <reference> <opt-whitespace> "::=" <opt-whitespace>
+//     <ref-or-head>     ::= <repeat> <opt-whitespace>
+//                         | <opt-whitespace> <opt-define>
+//     <group>           ::= "(" <opt-whitespace> <group-tail>
+//     <opt-define>      ::= "" | "::=" <opt-whitespace>
+//     <reference>       ::= "<" <rule-name> ">"
+//     <whitespace>      ::= " " | "\t" | <EOL> | <comment>
+//     <term>            ::= <literal> <case-flag> | <group>
 //     <literal>         ::= '"' <text1> '"' | "'" <text2> "'"
 //     <text1>           ::= "" | <character1> <text1>
 //     <text2>           ::= "" | <character2> <text2>
@@ -32,28 +47,41 @@ package bnf
 //     <character>       ::= <letter> | <digit> | <symbol> | <escape-sequence>
 //     <escape-sequence> ::= "\\" <escaped-char>
 //     <escaped-char>    ::= "t" | "n" | "r" | '"' | "\\"
+//                         | "x" <hex-digit> <hex-digit>
 //     <rule-name>       ::= <letter> <rule-name-tail>
 //     <rule-name-tail>  ::= "" | <rule-char> <rule-name-tail>
 //     <rule-char>       ::= <letter> | <digit> | "-"
 //
-//     <letter>          ::= "A" | "B" | "C" | "D" | "E" | "F" | "G" | "H" | \
-//                           "I" | "J" | "K" | "L" | "M" | "N" | "O" | "P" | \
-//                           "Q" | "R" | "S" | "T" | "U" | "V" | "W" | "X" | \
-//                           "Y" | "Z" | "a" | "b" | "c" | "d" | "e" | "f" | \
-//                           "g" | "h" | "i" | "j" | "k" | "l" | "m" | "n" | \
-//                           "o" | "p" | "q" | "r" | "s" | "t" | "u" | "v" | \
-//                           "w" | "x" | "y" | "z"
+//     <letter>          ::= "A" | "B" | "C" | "D" | "E" | "F" | "G" | "H"
+//                         | "I" | "J" | "K" | "L" | "M" | "N" | "O" | "P"
+//                         | "Q" | "R" | "S" | "T" | "U" | "V" | "W" | "X"
+//                         | "Y" | "Z" | "a" | "b" | "c" | "d" | "e" | "f"
+//                         | "g" | "h" | "i" | "j" | "k" | "l" | "m" | "n"
+//                         | "o" | "p" | "q" | "r" | "s" | "t" | "u" | "v"
+//                         | "w" | "x" | "y" | "z"
 //
-//     <digit>           ::= "0" | "1" | "2" | "3" | "4" | "5" | "6" | "7" | \
-//                           "8" | "9"
+//     <digit>           ::= "0" | "1" | "2" | "3" | "4" | "5" | "6" | "7"
+//                         | "8" | "9"
 //
-//     <symbol>          ::= "|" | " " | "!" | "#" | "$" | "%" | "&" | "(" | \
-//                           ")" | "*" | "+" | "," | "-" | "." | "/" | ":" | \
-//                           ";" | ">" | "=" | "<" | "?" | "@" | "[" | "\" | \
-//                           "]" | "^" | "_" | "`" | "{" | "}" | "~"
+//     <symbol>          ::= "|" | " " | "!" | "#" | "$" | "%" | "&" | "("
+//                         | ")" | "*" | "+" | "," | "-" | "." | "/" | ":"
+//                         | ";" | ">" | "=" | "<" | "?" | "@" | "[" | "]"
+//                         | "^" | "_" | "`" | "{" | "}" | "~"
 //
-//     <opt-whitespace>  ::= " " <opt-whitespace> | ""
+//     <hex-digit>       ::= <digit> | "A" | "B" | "C" | "D" | "E" | "F" | "a"
+//                         | "b" | "c" | "d" | "e" | "f"
+//
+//     <opt-whitespace>  ::= <whitespace> <opt-whitespace> | ""
 //     <EOL>             ::= "\n" | "\r\n"
+//     <case-flag>       ::= "" | "i"
+//     <factor>          ::= <term> <repeat-op>
+//     <repeat-op>       ::= "" | <repeat>
+//     <repeat>          ::= "*" | "+" | "?"
+//     <group-tail>      ::= ")" | <item> <group-tail>
+//     <comment>         ::= ";" <comment-text> | "#" <comment-text>
+//     <comment-text>    ::= "" | <EOL> | <comment-char> <comment-text>
+//     <comment-char>    ::= <letter> | <digit> | <symbol> | "\\" | "'" | '"'
+//                         | "\t" | <other-char>
 //
 func SelfGrammar() Grammar {
 	return Grammar{
@@ -70,19 +98,19 @@ func SelfGrammar() Grammar {
 									Name: "opt-whitespace",
 								},
 								SymbolNonTerminal{
-									Name: "content",
+									Name: "rule-head",
 								},
 								SymbolNonTerminal{
-									Name: "more-lines",
+									Name: "items",
 								},
 							},
 						},
 					},
 				},
 			},
-			{ //  1 <more-lines>
+			{ //  1 <items>
 				Head: SymbolNonTerminal{
-					Name: "more-lines",
+					Name: "items",
 				},
 				Tail: Substitution{
 					Sequences: []Sequence{
@@ -94,118 +122,134 @@ func SelfGrammar() Grammar {
 						{
 							Symbols: []Symbol{
 								SymbolNonTerminal{
-									Name: "EOL",
-								},
-								SymbolNonTerminal{
-									Name: "line",
+									Name: "item",
 								},
 								SymbolNonTerminal{
-									Name: "more-lines",
+									Name: "items",
 								},
 							},
 						},
 					},
 				},
 			},
-			{ //  2 <line>
+			{ //  2 <item>
 				Head: SymbolNonTerminal{
-					Name: "line",
+					Name: "item",
 				},
 				Tail: Substitution{
 					Sequences: []Sequence{
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "|",
+								},
+								SymbolNonTerminal{
+									Name: "opt-whitespace",
+								},
+							},
+						},
 						{
 							Symbols: []Symbol{
+								SymbolNonTerminal{
+									Name: "factor",
+								},
 								SymbolNonTerminal{
 									Name: "opt-whitespace",
 								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolNonTerminal{
+									Name: "reference",
+								},
 								SymbolNonTerminal{
-									Name: "opt-content",
+									Name: "ref-or-head",
 								},
-								// SymbolNonTerminal {
-								// 	Name: "opt-whitespace",
-								// },
 							},
 						},
 					},
 				},
 			},
-			{ //  3 <opt-content>
+			{ //  3 <rule-head>
 				Head: SymbolNonTerminal{
-					Name: "opt-content",
+					Name: "rule-head",
 				},
 				Tail: Substitution{
 					Sequences: []Sequence{
 						{
 							Symbols: []Symbol{
 								SymbolNonTerminal{
-									Name: "content",
+									Name: "reference",
+								},
+								SymbolNonTerminal{
+									Name: "opt-whitespace",
+								},
+								SymbolTerminal{
+									Name: "::=",
+								},
+								SymbolNonTerminal{
+									Name: "opt-whitespace",
 								},
-							},
-						},
-						{
-							Symbols: []Symbol{
-								SymbolNothing{},
 							},
 						},
 					},
 				},
 			},
-			{ //  4 <content>
+			{ //  4 <ref-or-head>
 				Head: SymbolNonTerminal{
-					Name: "content",
+					Name: "ref-or-head",
 				},
 				Tail: Substitution{
 					Sequences: []Sequence{
 						{
 							Symbols: []Symbol{
-								SymbolTerminal{
-									Name: "<",
-								},
 								SymbolNonTerminal{
-									Name: "rule-name",
-								},
-								SymbolTerminal{
-									Name: ">",
+									Name: "repeat",
 								},
 								SymbolNonTerminal{
 									Name: "opt-whitespace",
 								},
-								SymbolTerminal{
-									Name: "::=",
-								},
+							},
+						},
+						{
+							Symbols: []Symbol{
 								SymbolNonTerminal{
 									Name: "opt-whitespace",
 								},
 								SymbolNonTerminal{
-									Name: "expression",
+									Name: "opt-define",
 								},
 							},
 						},
 					},
 				},
 			},
-			{ //  5 <expression>
+			{ //  5 <group>
 				Head: SymbolNonTerminal{
-					Name: "expression",
+					Name: "group",
 				},
 				Tail: Substitution{
 					Sequences: []Sequence{
 						{
 							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "(",
+								},
 								SymbolNonTerminal{
-									Name: "list",
+									Name: "opt-whitespace",
 								},
 								SymbolNonTerminal{
-									Name: "expression-tail",
+									Name: "group-tail",
 								},
 							},
 						},
 					},
 				},
 			},
-			{ //  6 <expression-tail>
+			{ //  6 <opt-define>
 				Head: SymbolNonTerminal{
-					Name: "expression-tail",
+					Name: "opt-define",
 				},
 				Tail: Substitution{
 					Sequences: []Sequence{
@@ -217,65 +261,69 @@ func SelfGrammar() Grammar {
 						{
 							Symbols: []Symbol{
 								SymbolTerminal{
-									Name: "|",
+									Name: "::=",
 								},
 								SymbolNonTerminal{
 									Name: "opt-whitespace",
 								},
-								SymbolNonTerminal{
-									Name: "list",
-								},
-								SymbolNonTerminal{
-									Name: "expression-tail",
-								},
 							},
 						},
 					},
 				},
 			},
-			{ //  7 <list>
+			{ //  7 <reference>
 				Head: SymbolNonTerminal{
-					Name: "list",
+					Name: "reference",
 				},
 				Tail: Substitution{
 					Sequences: []Sequence{
 						{
 							Symbols: []Symbol{
-								SymbolNonTerminal{
-									Name: "term",
+								SymbolTerminal{
+									Name: "<",
 								},
 								SymbolNonTerminal{
-									Name: "opt-whitespace",
+									Name: "rule-name",
 								},
-								SymbolNonTerminal{
-									Name: "list-tail",
+								SymbolTerminal{
+									Name: ">",
 								},
 							},
 						},
 					},
 				},
 			},
-			{ //  8 <list-tail>
+			{ //  8 <whitespace>
 				Head: SymbolNonTerminal{
-					Name: "list-tail",
+					Name: "whitespace",
 				},
 				Tail: Substitution{
 					Sequences: []Sequence{
 						{
 							Symbols: []Symbol{
-								SymbolNothing{},
+								SymbolTerminal{
+									Name: " ",
+								},
 							},
 						},
 						{
 							Symbols: []Symbol{
-								SymbolNonTerminal{
-									Name: "term",
+								SymbolTerminal{
+									Name: "\t",
 								},
+							},
+						},
+						{
+							Symbols: []Symbol{
 								SymbolNonTerminal{
-									Name: "opt-whitespace",
+									Name: "EOL",
 								},
+							},
+						},
+						{
+							Symbols: []Symbol{
 								SymbolNonTerminal{
-									Name: "list-tail",
+									Name: "comment",
 								},
 							},
 						},
@@ -293,17 +341,15 @@ func SelfGrammar() Grammar {
 								SymbolNonTerminal{
 									Name: "literal",
 								},
+								SymbolNonTerminal{
+									Name: "case-flag",
+								},
 							},
-						}, {
+						},
+						{
 							Symbols: []Symbol{
-								SymbolTerminal{
-									Name: "<",
-								},
 								SymbolNonTerminal{
-									Name: "rule-name",
-								},
-								SymbolTerminal{
-									Name: ">",
+									Name: "group",
 								},
 							},
 						},
@@ -536,6 +582,19 @@ func SelfGrammar() Grammar {
 								},
 							},
 						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "x", // byte of two hex digits
+								},
+								SymbolNonTerminal{
+									Name: "hex-digit",
+								},
+								SymbolNonTerminal{
+									Name: "hex-digit",
+								},
+							},
+						},
 					},
 				},
 			},
@@ -1291,8 +1350,8 @@ func SelfGrammar() Grammar {
 					Sequences: []Sequence{
 						{
 							Symbols: []Symbol{
-								SymbolTerminal{
-									Name: " ",
+								SymbolNonTerminal{
+									Name: "whitespace",
 								},
 								SymbolNonTerminal{
 									Name: "opt-whitespace",
@@ -1330,6 +1389,1456 @@ func SelfGrammar() Grammar {
 					},
 				},
 			},
+			{ // 26 <case-flag>
+				Head: SymbolNonTerminal{
+					Name: "case-flag",
+				},
+				Tail: Substitution{
+					Sequences: []Sequence{
+						{
+							Symbols: []Symbol{
+								SymbolNothing{},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "i",
+								},
+							},
+						},
+					},
+				},
+			},
+			{ // 27 <factor>
+				Head: SymbolNonTerminal{
+					Name: "factor",
+				},
+				Tail: Substitution{
+					Sequences: []Sequence{
+						{
+							Symbols: []Symbol{
+								SymbolNonTerminal{
+									Name: "term",
+								},
+								SymbolNonTerminal{
+									Name: "repeat-op",
+								},
+							},
+						},
+					},
+				},
+			},
+			{ // 28 <repeat-op>
+				Head: SymbolNonTerminal{
+					Name: "repeat-op",
+				},
+				Tail: Substitution{
+					Sequences: []Sequence{
+						{
+							Symbols: []Symbol{
+								SymbolNothing{},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolNonTerminal{
+									Name: "repeat",
+								},
+							},
+						},
+					},
+				},
+			},
+			{ // 29 <repeat>
+				Head: SymbolNonTerminal{
+					Name: "repeat",
+				},
+				Tail: Substitution{
+					Sequences: []Sequence{
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "*",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "+",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "?",
+								},
+							},
+						},
+					},
+				},
+			},
+			{ // 30 <group-tail>
+				Head: SymbolNonTerminal{
+					Name: "group-tail",
+				},
+				Tail: Substitution{
+					Sequences: []Sequence{
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: ")",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolNonTerminal{
+									Name: "item",
+								},
+								SymbolNonTerminal{
+									Name: "group-tail",
+								},
+							},
+						},
+					},
+				},
+			},
+			{ // 31 <comment>
+				Head: SymbolNonTerminal{
+					Name: "comment",
+				},
+				Tail: Substitution{
+					Sequences: []Sequence{
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: ";",
+								},
+								SymbolNonTerminal{
+									Name: "comment-text",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "#",
+								},
+								SymbolNonTerminal{
+									Name: "comment-text",
+								},
+							},
+						},
+					},
+				},
+			},
+			{ // 32 <comment-text>
+				Head: SymbolNonTerminal{
+					Name: "comment-text",
+				},
+				Tail: Substitution{
+					Sequences: []Sequence{
+						{
+							Symbols: []Symbol{
+								SymbolNothing{},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolNonTerminal{
+									Name: "EOL",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolNonTerminal{
+									Name: "comment-char",
+								},
+								SymbolNonTerminal{
+									Name: "comment-text",
+								},
+							},
+						},
+					},
+				},
+			},
+			{ // 33 <comment-char>
+				Head: SymbolNonTerminal{
+					Name: "comment-char",
+				},
+				Tail: Substitution{
+					Sequences: []Sequence{
+						{
+							Symbols: []Symbol{
+								SymbolNonTerminal{
+									Name: "letter",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolNonTerminal{
+									Name: "digit",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolNonTerminal{
+									Name: "symbol",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\\",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "'",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\"",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\t",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolNonTerminal{
+									Name: "other-char",
+								},
+							},
+						},
+					},
+				},
+			},
+			{ // 34 <other-char>
+				Head: SymbolNonTerminal{
+					Name: "other-char",
+				},
+				Tail: Substitution{
+					Sequences: []Sequence{
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x01",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x02",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x03",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x04",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x05",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x06",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x07",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x08",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x0b",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x0c",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x0e",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x0f",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x10",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x11",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x12",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x13",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x14",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x15",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x16",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x17",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x18",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x19",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x1a",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x1b",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x1c",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x1d",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x1e",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x1f",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x7f",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x80",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x81",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x82",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x83",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x84",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x85",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x86",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x87",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x88",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x89",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x8a",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x8b",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x8c",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x8d",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x8e",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x8f",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x90",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x91",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x92",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x93",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x94",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x95",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x96",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x97",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x98",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x99",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x9a",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x9b",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x9c",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x9d",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x9e",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\x9f",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xa0",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xa1",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xa2",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xa3",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xa4",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xa5",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xa6",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xa7",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xa8",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xa9",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xaa",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xab",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xac",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xad",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xae",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xaf",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xb0",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xb1",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xb2",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xb3",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xb4",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xb5",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xb6",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xb7",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xb8",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xb9",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xba",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xbb",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xbc",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xbd",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xbe",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xbf",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xc0",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xc1",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xc2",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xc3",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xc4",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xc5",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xc6",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xc7",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xc8",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xc9",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xca",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xcb",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xcc",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xcd",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xce",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xcf",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xd0",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xd1",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xd2",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xd3",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xd4",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xd5",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xd6",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xd7",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xd8",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xd9",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xda",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xdb",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xdc",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xdd",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xde",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xdf",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xe0",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xe1",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xe2",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xe3",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xe4",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xe5",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xe6",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xe7",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xe8",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xe9",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xea",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xeb",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xec",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xed",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xee",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xef",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xf0",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xf1",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xf2",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xf3",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xf4",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xf5",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xf6",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xf7",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xf8",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xf9",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xfa",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xfb",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xfc",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xfd",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xfe",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "\xff",
+								},
+							},
+						},
+					},
+				},
+			},
+			{ // 35 <hex-digit>
+				Head: SymbolNonTerminal{
+					Name: "hex-digit",
+				},
+				Tail: Substitution{
+					Sequences: []Sequence{
+						{
+							Symbols: []Symbol{
+								SymbolNonTerminal{
+									Name: "digit",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "A",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "B",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "C",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "D",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "E",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "F",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "a",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "b",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "c",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "d",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "e",
+								},
+							},
+						},
+						{
+							Symbols: []Symbol{
+								SymbolTerminal{
+									Name: "f",
+								},
+							},
+						},
+					},
+				},
+			},
 		},
 	}
 }
@@ -31,7 +31,25 @@ func enumerate(arr []string) map[string]int {
 }
 
 func terminalFirsts(t bnf.SymbolTerminal) bs.ByteSet {
-	return bs.New(t.Name[0])
+	c := t.Name[0]
+	if !t.CaseInsensitive {
+		return bs.New(c)
+	}
+
+	switch {
+	case 'a' <= c && c <= 'z':
+		return bs.New(c, c - ('a' - 'A'))
+	case 'A' <= c && c <= 'Z':
+		return bs.New(c, c + ('a' - 'A'))
+	}
+	return bs.New(c)
+}
+
+func terminalOp(t bnf.SymbolTerminal) parser.ParserOp {
+	if t.CaseInsensitive {
+		return parser.OpTerminalCaseInsensitive(t.Name)
+	}
+	return parser.OpTerminal(t.Name)
 }
 
 type tableGenerator struct {
@@ -327,11 +345,20 @@ func (tg *tableGenerator,
 		}
 
 		if seqFirsts.Contains(EOS) {
-			// For each term in ruleFollows add empty []ParserOp
-			// if term in ruleFollow is Epsilon(TypeNothing)
-			//     add empty []ParserOp ?to EOS?
+			// For each term in ruleFollows add sequence's non terminals,
+			// so nullable rules keep their nodes in tree. Sequence of
+			// Epsilon(TypeNothing) only gets empty []ParserOp
 			seqFollows := tg.follows[ruleIndex]
 
+			var ops []parser.ParserOp
+			for _, symbol := range sequence.Symbols {
+				v, ok := symbol.(bnf.SymbolNonTerminal)
+				if !ok {
+					continue
+				}
+				ops = append(ops, parser.OpNonTerminal(tg.ruleMap[v.Name]))
+			}
+
 			for _, followTerm := range seqFollows.Bytes() {
 				if v, ok := res[byte(followTerm)]; ok {
 					fmt.Println(v)
@@ -339,7 +366,7 @@ func (tg *tableGenerator,
 						fmt.Errorf("grammar lead to multiple parser op " +
 							"sets per table cell")
 				}
-				res[byte(followTerm)] = []parser.ParserOp{}
+				res[byte(followTerm)] = append([]parser.ParserOp{}, ops...)
 			}
 			// remove it to not add res
 			seqFirsts.Remove(EOS)
@@ -350,7 +377,7 @@ func (tg *tableGenerator,
 				switch v := symbol.(type) {
 				case bnf.SymbolTerminal:
 					res[byte(term)] = append(res[byte(term)],
-						parser.OpTerminal(v.Name))
+						terminalOp(v))
 
 				case bnf.SymbolNonTerminal:
 					ruleIndex, ok := tg.ruleMap[v.Name]
@@ -410,10 +437,17 @@ func (tg *tableGenerator) Run() (table *map[int]map[byte][]parser.ParserOp,
 	return tg.makeTable()
 }
 
+// Groups and repetitions of g are desugared first, so resulting table also
+// has rows for rules generated by bnf.Desugar
 func FromGrammar(g bnf.Grammar) (table *map[int]map[byte][]parser.ParserOp,
 	rowNames *map[int]string,
 	err error) {
 
+	g, err = bnf.Desugar(g)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	ruleHeads := collectRuleHeads(g)
 
 	var tablegen tableGenerator
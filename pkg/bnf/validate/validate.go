@@ -0,0 +1,304 @@
+// Checks of parse trees against grammar they are supposed to be parsed with.
+//
+// Tree is valid when it could be produced by parser built from grammar with
+// tablegen.FromGrammar: node of type i is node of rule g.Rules[i] which
+// childs match one of rule's alternatives (or single _nothing if rule has
+// alternative of nothing only), _literal nodes span text of terminals, and
+// childs spans are contiguous and exactly cover their parent's span.
+package validate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/TooManySugar/ll1parser/pkg/bnf"
+	"github.com/TooManySugar/ll1parser/pkg/cst"
+	"github.com/TooManySugar/ll1parser/pkg/parser"
+)
+
+// Problem with tree node at Path. Path steps are type names with index among
+// parent's childs, e.g. /pair[0]/item[2]
+type Violation struct {
+	Path string
+	Node cst.Node
+	Msg string
+}
+
+func (v Violation) Error() string {
+	return v.Path + ": " + v.Msg
+}
+
+type validator struct {
+	g *bnf.Grammar
+	src string
+	ruleMap map[string]int
+	empty []bool
+
+	violations []Violation
+}
+
+func newValidator(g *bnf.Grammar, src string) *validator {
+	v := validator{
+		g: g,
+		src: src,
+		ruleMap: make(map[string]int, len(g.Rules)),
+		empty: make([]bool, len(g.Rules)),
+	}
+	for i := range g.Rules {
+		v.ruleMap[g.Rules[i].Head.Name] = i
+	}
+	v.findEmpty()
+	return &v
+}
+
+// Marks rules which have alternative made of nothing only, parser expands
+// such rules to single _nothing node
+func (v *validator) findEmpty() {
+	for i, rule := range v.g.Rules {
+		for _, sequence := range rule.Tail.Sequences {
+			empty := true
+			for _, symbol := range sequence.Symbols {
+				if _, ok := symbol.(bnf.SymbolNothing); !ok {
+					empty = false
+					break
+				}
+			}
+			if empty {
+				v.empty[i] = true
+				break
+			}
+		}
+	}
+}
+
+func (v *validator) nodeTypeName(nodeType int) string {
+	switch {
+	case nodeType == parser.BuiltinTerminal:
+		return "_literal"
+	case nodeType == parser.BuiltinNothing:
+		return "_nothing"
+	case 0 <= nodeType && nodeType < len(v.g.Rules):
+		return v.g.Rules[nodeType].Head.Name
+	}
+	return fmt.Sprintf("Unknown_%d", nodeType)
+}
+
+func (v *validator) violation(path string, node cst.Node,
+                              format string, a ...any) {
+	v.violations = append(v.violations, Violation{
+		Path: path,
+		Node: node,
+		Msg: fmt.Sprintf(format, a...),
+	})
+}
+
+// Returns whether a and b are equal ignoring case of ASCII letters
+func equalFoldASCII(a string, b string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := 0; i < len(a); i++ {
+		ca, cb := a[i], b[i]
+		if 'A' <= ca && ca <= 'Z' {
+			ca += 'a' - 'A'
+		}
+		if 'A' <= cb && cb <= 'Z' {
+			cb += 'a' - 'A'
+		}
+		if ca != cb {
+			return false
+		}
+	}
+	return true
+}
+
+func (v *validator) symbolMatches(symbol bnf.Symbol, child cst.Node) bool {
+	switch s := symbol.(type) {
+	case bnf.SymbolTerminal:
+		if child.Type() != parser.BuiltinTerminal {
+			return false
+		}
+		text := v.src[child.Pos():child.End()]
+		if s.CaseInsensitive {
+			return equalFoldASCII(text, s.Name)
+		}
+		return text == s.Name
+
+	case bnf.SymbolNonTerminal:
+		i, ok := v.ruleMap[s.Name]
+		return ok && child.Type() == i
+	}
+	return false
+}
+
+func (v *validator) sequenceMatches(sequence *bnf.Sequence,
+                                    childs []cst.Node) bool {
+	if len(sequence.Symbols) != len(childs) {
+		return false
+	}
+	for i, symbol := range sequence.Symbols {
+		if !v.symbolMatches(symbol, childs[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Describes childs as sequence of symbols they could be parsed from
+func (v *validator) describeChilds(childs []cst.Node) string {
+	sb := strings.Builder{}
+	for i, child := range childs {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		if child.Type() == parser.BuiltinTerminal {
+			sb.WriteString(bnf.SymbolTerminal{
+				Name: v.src[child.Pos():child.End()],
+			}.String())
+			continue
+		}
+		sb.WriteString(bnf.SymbolNonTerminal{
+			Name: v.nodeTypeName(child.Type()),
+		}.String())
+	}
+	return sb.String()
+}
+
+// Checks node's span, returns false if it is out of source and node's text
+// can't be checked
+func (v *validator) checkSpan(path string, node cst.Node) bool {
+	if node.Pos() < 0 || node.End() < node.Pos() || node.End() > len(v.src) {
+		v.violation(path, node, "span [%d:%d] is out of source of length %d",
+		            node.Pos(), node.End(), len(v.src))
+		return false
+	}
+	return true
+}
+
+// Checks that childs are contiguous and cover node's span
+func (v *validator) checkChildSpans(path string, node cst.Node) {
+	pos := node.Pos()
+	for i, child := range node.Childs() {
+		if child.Pos() != pos {
+			v.violation(path, node,
+			            "child %d starts at %d, expected %d", i, child.Pos(), pos)
+		}
+		pos = child.End()
+	}
+	if pos != node.End() {
+		v.violation(path, node, "childs end at %d, expected %d",
+		            pos, node.End())
+	}
+}
+
+func (v *validator) checkRuleChilds(path string, node cst.Node) {
+	rule := &v.g.Rules[node.Type()]
+	childs := node.Childs()
+
+	if len(childs) == 1 && childs[0].Type() == parser.BuiltinNothing {
+		if !v.empty[node.Type()] {
+			v.violation(path, node, "rule <%s> can't be empty", rule.Head.Name)
+		}
+		return
+	}
+
+	for i := range rule.Tail.Sequences {
+		if v.sequenceMatches(&rule.Tail.Sequences[i], childs) {
+			return
+		}
+	}
+
+	v.violation(path, node, "childs `%s` match no alternative of <%s>",
+	            v.describeChilds(childs), rule.Head.Name)
+}
+
+// Checks node and it's relation to it's childs. Returns false if childs
+// should not be checked
+func (v *validator) checkNode(path string, node cst.Node) bool {
+	childs := node.Childs()
+
+	switch t := node.Type(); {
+	case t == parser.BuiltinTerminal || t == parser.BuiltinNothing:
+		if len(childs) > 0 {
+			v.violation(path, node, "%s node has childs", v.nodeTypeName(t))
+		}
+		if t == parser.BuiltinNothing && node.Pos() != node.End() {
+			v.violation(path, node, "_nothing node is not empty")
+		}
+		return v.checkSpan(path, node)
+
+	case t < 0 || t >= len(v.g.Rules):
+		v.violation(path, node, "unknown node type %d", t)
+		return false
+	}
+
+	if !v.checkSpan(path, node) {
+		return false
+	}
+
+	if len(childs) == 0 {
+		v.violation(path, node, "rule node has no childs")
+		return true
+	}
+
+	for _, child := range childs {
+		if child.Pos() < node.Pos() || child.End() > node.End() ||
+		   child.Pos() > child.End() {
+			v.violation(path, node, "child span [%d:%d] is out of [%d:%d]",
+			            child.Pos(), child.End(), node.Pos(), node.End())
+			return false
+		}
+	}
+
+	v.checkChildSpans(path, node)
+	v.checkRuleChilds(path, node)
+	return true
+}
+
+func (v *validator) run(root cst.Node) {
+	it := cst.NewIterator(root)
+	// paths[i] is path of last visited node at depth i, counts[i] is number
+	// of visited childs of that node
+	var paths []string
+	var counts []int
+
+	for it.Next() {
+		depth := it.Depth()
+		node := it.Node()
+
+		parentPath, index := "", 0
+		if depth > 0 {
+			parentPath = paths[depth - 1]
+			index = counts[depth - 1]
+			counts[depth - 1]++
+		}
+		path := fmt.Sprintf("%s/%s[%d]",
+		                    parentPath, v.nodeTypeName(node.Type()), index)
+
+		paths = append(paths[:depth], path)
+		counts = append(counts[:depth], 0)
+
+		if !v.checkNode(path, node) {
+			it.SkipChildren()
+		}
+	}
+}
+
+// Returns violations of grammar g by tree with root spanning src, empty if
+// tree is valid. Root can be of any rule and span any part of src. Grammar
+// is desugared as by table generator, so trees may have nodes of generated
+// rules. Grammar which can't be desugared is reported as violation at root
+func Validate(root cst.Node, g bnf.Grammar, src string) []Violation {
+	g, err := bnf.Desugar(g)
+	if err != nil {
+		return []Violation{{
+			Path: "/",
+			Node: root,
+			Msg: fmt.Sprintf("can't desugar grammar: %s", err.Error()),
+		}}
+	}
+
+	v := newValidator(&g, src)
+	v.run(root)
+	return v.violations
+}
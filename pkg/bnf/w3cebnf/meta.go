@@ -0,0 +1,102 @@
+package w3cebnf
+
+import (
+	"sync"
+
+	"github.com/TooManySugar/ll1parser/pkg/bnf"
+	"github.com/TooManySugar/ll1parser/pkg/bnf/internal/notation"
+)
+
+// Syntax of W3C EBNF text. As in BNF syntax of this library rule head is
+// name followed by "::=", so rules are flat lists of items split on heads
+// and "|" by converter, which also applies "-".
+//
+// Empty alternatives are written last, so table generator reports rules
+// which are not LL(1) instead of preferring longer alternative
+const metaGrammarText = `
+<grammar>      ::= <ws> <rule-head> <items>
+<rule-head>    ::= <name> <head-tail>
+<head-tail>    ::= <ws-char> <ws> <define> | <define>
+<define>       ::= "::=" <ws>
+
+; name is followed by it's suffix, whitespace, "::=" when it is head of
+; next rule, or next item
+<items>        ::= <item> <items> | <name> <after-name> | ""
+<after-name>   ::= <suffix-op> <ws> <items> | <ws-char> <ws> <ref-end>
+                 | <define> <items> | <item> <items> | ""
+<ref-end>      ::= <define> <items> | <items>
+<group-items>  ::= <item> <items> | <name> <after-name>
+
+<item>         ::= "|" <ws> | "-" <ws> | <primary> <suffix-ws>
+<suffix-ws>    ::= <suffix-op> <ws> | <ws>
+<suffix-op>    ::= "?" | "*" | "+"
+<primary>      ::= <string> | <char-code> | <char-class> | <group>
+<group>        ::= "(" <ws> <group-items> ")"
+
+<string>       ::= '"' <dq-text> '"' | "'" <sq-text> "'"
+<dq-text>      ::= <dq-char> <dq-text> | ""
+<sq-text>      ::= <sq-char> <sq-text> | ""
+; code without hex digits is reported by converter
+<char-code>    ::= "#x" <hex-tail>
+; empty alternative is first, so hex digits are taken greedily
+<hex-tail>     ::= "" | <hex> <hex-tail>
+
+; "-" between atoms makes range, first or last "-" is itself
+<char-class>   ::= "[" <class-start>
+<class-start>  ::= "^" <class-first> | <class-first>
+<class-first>  ::= "-" <class-rest> | <class-atom> <after-atom>
+<class-rest>   ::= "]" | "-" "]" | <class-atom> <after-atom>
+<after-atom>   ::= "-" <after-dash> | "]" | <class-atom> <after-atom>
+<after-dash>   ::= "]" | <class-atom> <class-rest>
+; "#" not followed by "x" is itself
+<class-atom>   ::= "#" <code-tail> | <class-char>
+<code-tail>    ::= "" | "x" <hex-tail>
+
+<name>         ::= <name-start> <name-tail>
+<name-tail>    ::= <name-char> <name-tail> | ""
+
+<ws>           ::= <ws-char> <ws> | ""
+<ws-char>      ::= " " | "\t" | "\n" | "\r" | <comment>
+<comment>      ::= "/*" <comment-body>
+<comment-body> ::= "*" <comment-star> | <comment-char> <comment-body>
+<comment-star> ::= "/" | "*" <comment-star> | <comment-inner> <comment-body>
+`
+
+// Byte classes of syntax, too long to be written in BNF
+func charClasses() []bnf.Rule {
+	return []bnf.Rule{
+		notation.CharClass("name-start", 'A', 'Z', '_', '_', 'a', 'z'),
+		notation.CharClass("name-char", '0', '9', 'A', 'Z', '_', '_', 'a', 'z'),
+		notation.CharClass("hex", '0', '9', 'A', 'F', 'a', 'f'),
+		notation.CharClass("dq-char", '\t', '\t', 0x20, 0x21, 0x23, 0xFF),
+		notation.CharClass("sq-char", '\t', '\t', 0x20, 0x26, 0x28, 0xFF),
+		// not "#", "-", "]" and "^"
+		notation.CharClass("class-char", 0x20, 0x22, 0x24, 0x2C, 0x2E, 0x5C,
+		                   0x5F, 0xFF),
+		// not "*"
+		notation.CharClass("comment-char", 0x01, 0x29, 0x2B, 0xFF),
+		// not "*" and "/"
+		notation.CharClass("comment-inner", 0x01, 0x29, 0x2B, 0x2E, 0x30, 0xFF),
+	}
+}
+
+// W3C EBNF parser with node types of it's grammar, built once on first use
+var meta struct {
+	once sync.Once
+	err error
+
+	syntax *notation.Syntax
+}
+
+func loadMeta() error {
+	meta.once.Do(func() {
+		syntax, err := notation.NewSyntax("W3C EBNF", metaGrammarText,
+		                                  charClasses()...)
+		if err != nil {
+			meta.err = err
+			return
+		}
+		meta.syntax = syntax
+	})
+	return meta.err
+}
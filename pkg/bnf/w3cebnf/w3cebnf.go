@@ -0,0 +1,629 @@
+// Reading of grammars written in W3C EBNF, notation of XML specification, to
+// bnf.Grammar ready for tablegen.FromGrammar:
+//
+//     g, err := w3cebnf.Parse(src)
+//     ...
+//     table, names, err := tablegen.FromGrammar(*g)
+//
+// EBNF text itself is read with LL1 parser of this library. Rules are
+// written as `symbol ::= expression` and keep order of the text, so first
+// rule is start rule. Names are case-sensitive and made of letters, digits
+// and "_". Comments are written between /* and */, well-formedness and
+// validity constraints ([ wfc: ... ], [ vc: ... ]) are skipped.
+//
+// Parser matches bytes, so character codes (#xN) must be in range 1 to 255.
+// Ranges of character classes are clamped to it: [#x20-#xD7FF] matches bytes
+// #x20 to #xFF and alternative of class listing only characters above #xFF,
+// like [#x10000-#x10FFFF], is dropped. Non-ASCII characters written in class
+// stand for their UTF-8 bytes. Character classes become group of single byte
+// alternatives and negated classes match bytes not listed. A?, A* and A+
+// become bnf.SymbolRepetition.
+//
+// A - B is desugared only when A and B are character sets: character codes,
+// classes, single character strings, groups and rules of their alternatives
+// and differences. Other differences can't be expressed with context free
+// grammar and are reported as errors
+package w3cebnf
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/TooManySugar/ll1parser/pkg/bnf"
+	"github.com/TooManySugar/ll1parser/pkg/bnf/internal/notation"
+	"github.com/TooManySugar/ll1parser/pkg/cst"
+)
+
+// Operator or factor of expression
+type unit struct {
+	// "|", "-" or "::=" for name of rule head, empty for factors
+	op string
+	// name or primary node of factor and head, item node of operator
+	node cst.Node
+	// suffix-op node of factor, nil when there is no suffix
+	suffix cst.Node
+}
+
+func (u unit) end() int {
+	if u.suffix != nil {
+		return u.suffix.End()
+	}
+	return u.node.End()
+}
+
+type rule struct {
+	head unit
+	body []unit
+	// offset after last unit of rule
+	end int
+}
+
+// Set of bytes matched by character set, index 0 is never set
+type byteSet [256]bool
+
+type converter struct {
+	notation.Source
+
+	rules []rule
+	// index of rule in rules by name
+	index map[string]int
+	// name nodes of references in order of text
+	refs []cst.Node
+}
+
+// Reports whether char class node is well-formedness or validity constraint
+func (c *converter) isConstraint(node cst.Node) bool {
+	text := c.Text(node)
+	text = strings.ToLower(strings.TrimLeft(text[1:], " \t"))
+	return strings.HasPrefix(text, "wfc:") || strings.HasPrefix(text, "vc:")
+}
+
+// Returns units of items in tree of root in order of text
+func (c *converter) units(root cst.Node) []unit {
+	var res []unit
+	nodes := c.Find(root, "rule-head", "item", "name", "suffix-op", "define")
+	for _, node := range nodes {
+		switch {
+		case c.Is(node, "rule-head"):
+			res = append(res, unit{op: "::=", node: node.Childs()[0]})
+
+		case c.Is(node, "name"):
+			res = append(res, unit{node: node})
+
+		// suffix and "::=" of name
+		case c.Is(node, "suffix-op"):
+			res[len(res) - 1].suffix = node
+
+		case c.Is(node, "define"):
+			res[len(res) - 1].op = "::="
+
+		default:
+			childs := node.Childs()
+			if !c.Is(childs[0], "primary") {
+				res = append(res, unit{op: c.Text(childs[0]), node: node})
+				continue
+			}
+
+			primary := childs[0].Childs()[0]
+			if c.Is(primary, "char-class") && c.isConstraint(primary) {
+				continue
+			}
+
+			u := unit{node: primary}
+			if suffix := childs[1].Childs(); len(suffix) > 0 &&
+			   c.Is(suffix[0], "suffix-op") {
+				u.suffix = suffix[0]
+			}
+			res = append(res, u)
+		}
+	}
+	return res
+}
+
+// Splits units into alternatives by "|", end is offset after last unit
+func (c *converter) expression(units []unit,
+                               end int) (bnf.Substitution, error) {
+	var res bnf.Substitution
+	// alternatives of classes above #xFF
+	var dropped []unit
+	start := 0
+	for i := 0; i <= len(units); i++ {
+		if i < len(units) && units[i].op != "|" {
+			continue
+		}
+
+		if i == start {
+			pos := end
+			if i < len(units) {
+				pos = units[i].node.Pos()
+			}
+			return res, c.Errorf(pos, "empty alternative")
+		}
+
+		clamped, err := c.isClamped(units[start:i])
+		if err != nil {
+			return res, err
+		}
+		if clamped {
+			dropped = append(dropped, units[start])
+			start = i + 1
+			continue
+		}
+
+		sequence, err := c.sequence(units[start:i])
+		if err != nil {
+			return res, err
+		}
+		res = notation.AppendAlternative(res, sequence)
+		start = i + 1
+	}
+
+	if len(res.Sequences) == 0 {
+		u := dropped[0]
+		return res, c.Errorf(u.node.Pos(), "%s matches no characters",
+		                     c.Text(u.node))
+	}
+	return res, nil
+}
+
+// Reports whether units are single character class which matches no bytes
+// as all it's characters are above #xFF
+func (c *converter) isClamped(units []unit) (bool, error) {
+	u := units[0]
+	if len(units) != 1 || u.op != "" || u.suffix != nil ||
+	   !c.Is(u.node, "char-class") {
+		return false, nil
+	}
+	// negated class matches no bytes only when it lists all of them
+	if strings.HasPrefix(c.Text(u.node), "[^") {
+		return false, nil
+	}
+
+	set, err := c.classSet(u.node)
+	if err != nil {
+		return false, err
+	}
+	return *set == byteSet{}, nil
+}
+
+// Returns end of operands joined by "-" from units[start]
+func differenceEnd(units []unit, start int) int {
+	end := start + 1
+	for end + 1 < len(units) && units[end].op == "-" &&
+	    units[end + 1].op == "" {
+		end += 2
+	}
+	return end
+}
+
+func (c *converter) sequence(units []unit) (bnf.Sequence, error) {
+	var res bnf.Sequence
+	for i := 0; i < len(units); {
+		u := units[i]
+		if u.op != "" {
+			return res, c.Errorf(u.node.Pos(), "%s has no left operand", u.op)
+		}
+
+		end := differenceEnd(units, i)
+		if end < len(units) && units[end].op == "-" {
+			return res, c.Errorf(units[end].node.Pos(),
+			                     "- has no right operand")
+		}
+
+		if end == i + 1 {
+			symbol, err := c.factor(u)
+			if err != nil {
+				return res, err
+			}
+			res.Symbols = append(res.Symbols, symbol)
+			i = end
+			continue
+		}
+
+		set, err := c.difference(units[i:end])
+		if err != nil {
+			return res, err
+		}
+		symbol, err := c.setSymbol(*set, u.node.Pos(),
+		                           c.Src[u.node.Pos():units[end - 1].end()])
+		if err != nil {
+			return res, err
+		}
+		res.Symbols = append(res.Symbols, symbol)
+		i = end
+	}
+	return res, nil
+}
+
+func (c *converter) factor(u unit) (bnf.Symbol, error) {
+	var symbol bnf.Symbol
+	switch node := u.node; {
+	case c.Is(node, "name"):
+		c.refs = append(c.refs, node)
+		symbol = bnf.SymbolNonTerminal{Name: c.Text(node)}
+
+	case c.Is(node, "string"):
+		text := c.Text(node)
+		text = text[1:len(text) - 1]
+		if text == "" {
+			symbol = bnf.SymbolNothing{}
+		} else {
+			symbol = bnf.SymbolTerminal{Name: text}
+		}
+
+	case c.Is(node, "char-code"):
+		v, err := c.byteValue(node)
+		if err != nil {
+			return nil, err
+		}
+		symbol = bnf.SymbolTerminal{Name: string([]byte{v})}
+
+	case c.Is(node, "char-class"):
+		set, err := c.classSet(node)
+		if err != nil {
+			return nil, err
+		}
+		symbol, err = c.setSymbol(*set, node.Pos(), c.Text(node))
+		if err != nil {
+			return nil, err
+		}
+
+	default:
+		group, err := c.group(node)
+		if err != nil {
+			return nil, err
+		}
+		symbol = group
+	}
+
+	if u.suffix == nil {
+		return symbol, nil
+	}
+
+	switch c.Text(u.suffix) {
+	case "?":
+		return bnf.SymbolRepetition{Symbol: symbol, Min: 0, Max: 1}, nil
+	case "*":
+		return bnf.SymbolRepetition{Symbol: symbol, Min: 0, Max: -1}, nil
+	}
+	return bnf.SymbolRepetition{Symbol: symbol, Min: 1, Max: -1}, nil
+}
+
+func (c *converter) groupUnits(node cst.Node) ([]unit, error) {
+	units := c.units(node.Childs()[2])
+	for _, u := range units {
+		if u.op == "::=" {
+			return nil, c.Errorf(u.node.Pos(),
+			                     "unexpected rule head %s in group",
+			                     c.Text(u.node))
+		}
+	}
+	return units, nil
+}
+
+func (c *converter) group(node cst.Node) (bnf.Symbol, error) {
+	units, err := c.groupUnits(node)
+	if err != nil {
+		return nil, err
+	}
+
+	alternatives, err := c.expression(units, node.End() - 1)
+	if err != nil {
+		return nil, err
+	}
+	return bnf.SymbolGroup{Alternatives: alternatives}, nil
+}
+
+// Returns code point of #xN at offset pos of source
+func (c *converter) codeValue(pos int, code string) (int, error) {
+	if len(code) == len("#x") {
+		return 0, c.Errorf(pos, "character code %s has no hex digits", code)
+	}
+
+	v, err := strconv.ParseUint(code[2:], 16, 32)
+	if err != nil || v > unicode.MaxRune {
+		return 0, c.Errorf(pos, "character %s is out of Unicode range", code)
+	}
+	return int(v), nil
+}
+
+func (c *converter) byteValue(node cst.Node) (byte, error) {
+	v, err := c.codeValue(node.Pos(), c.Text(node))
+	switch {
+	case err != nil:
+		return 0, err
+	case v > 0xFF:
+		return 0, c.Errorf(node.Pos(), "character %s is out of byte range",
+		                   c.Text(node))
+	case v == 0:
+		return 0, c.Errorf(node.Pos(), "zero byte marks end of input and " +
+		                               "can't be matched")
+	}
+	return byte(v), nil
+}
+
+func isHex(b byte) bool {
+	return '0' <= b && b <= '9' || 'A' <= b && b <= 'F' ||
+	       'a' <= b && b <= 'f'
+}
+
+// Returns code point of class atom at body[i] and offset after it, pos is
+// offset of body in source. Non-ASCII characters are taken byte by byte
+func (c *converter) classAtom(body string, i int,
+                              pos int) (int, int, error) {
+	if !strings.HasPrefix(body[i:], "#x") {
+		return int(body[i]), i + 1, nil
+	}
+
+	end := i + 2
+	for end < len(body) && isHex(body[end]) {
+		end++
+	}
+	v, err := c.codeValue(pos + i, body[i:end])
+	return v, end, err
+}
+
+// Returns set of bytes matched by char class node
+func (c *converter) classSet(node cst.Node) (*byteSet, error) {
+	text := c.Text(node)
+	body := text[1:len(text) - 1]
+	pos := node.Pos() + 1
+
+	negated := strings.HasPrefix(body, "^")
+	if negated {
+		body = body[1:]
+		pos++
+	}
+
+	var set byteSet
+	for i := 0; i < len(body); {
+		// "-" is itself at start and end of class and after range
+		if body[i] == '-' {
+			set['-'] = true
+			i++
+			continue
+		}
+
+		first, next, err := c.classAtom(body, i, pos)
+		if err != nil {
+			return nil, err
+		}
+		last := first
+		if next + 1 < len(body) && body[next] == '-' {
+			last, next, err = c.classAtom(body, next + 1, pos)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if first > last {
+			return nil, c.Errorf(pos + i, "range %s is empty", body[i:next])
+		}
+
+		// range is clamped to bytes
+		if first < 1 {
+			first = 1
+		}
+		for v := first; v <= last && v <= 0xFF; v++ {
+			set[v] = true
+		}
+		i = next
+	}
+
+	if negated {
+		for v := 1; v < len(set); v++ {
+			set[v] = !set[v]
+		}
+	}
+	return &set, nil
+}
+
+// Returns set of bytes matched by factor u, or nil if it isn't character
+// set. visiting holds names of rules sets are taken from
+func (c *converter) charSet(u unit, visiting map[string]bool) (*byteSet,
+                                                               error) {
+	if u.op != "" || u.suffix != nil {
+		return nil, nil
+	}
+
+	node := u.node
+	switch {
+	case c.Is(node, "name"):
+		name := c.Text(node)
+		i, ok := c.index[name]
+		if !ok || visiting[name] {
+			return nil, nil
+		}
+
+		visiting[name] = true
+		defer delete(visiting, name)
+		return c.unitsSet(c.rules[i].body, visiting)
+
+	case c.Is(node, "string"):
+		text := c.Text(node)
+		if len(text) != 3 {
+			return nil, nil
+		}
+		var set byteSet
+		set[text[1]] = true
+		return &set, nil
+
+	// as in classes, characters above #xFF are clamped away
+	case c.Is(node, "char-code"):
+		v, err := c.codeValue(node.Pos(), c.Text(node))
+		if err != nil {
+			return nil, err
+		}
+		var set byteSet
+		if 0 < v && v <= 0xFF {
+			set[v] = true
+		}
+		return &set, nil
+
+	case c.Is(node, "char-class"):
+		return c.classSet(node)
+	}
+
+	units, err := c.groupUnits(node)
+	if err != nil {
+		return nil, err
+	}
+	return c.unitsSet(units, visiting)
+}
+
+// Returns set of bytes matched by alternatives of units, or nil if some
+// alternative isn't character set
+func (c *converter) unitsSet(units []unit, visiting map[string]bool) (*byteSet,
+                                                                     error) {
+	var res byteSet
+	for start := 0; start < len(units); {
+		end := differenceEnd(units, start)
+		if end < len(units) && units[end].op != "|" {
+			return nil, nil
+		}
+
+		set, _, err := c.differenceSet(units[start:end], visiting)
+		if set == nil || err != nil {
+			return nil, err
+		}
+		for v := range set {
+			res[v] = res[v] || set[v]
+		}
+		start = end + 1
+	}
+	return &res, nil
+}
+
+// Returns set of bytes matched by operands joined by "-", or nil with operand
+// which isn't character set
+func (c *converter) differenceSet(units []unit,
+                                  visiting map[string]bool) (*byteSet, unit,
+                                                             error) {
+	res, err := c.charSet(units[0], visiting)
+	if res == nil || err != nil {
+		return nil, units[0], err
+	}
+
+	for i := 2; i < len(units); i += 2 {
+		set, err := c.charSet(units[i], visiting)
+		if set == nil || err != nil {
+			return nil, units[i], err
+		}
+		for v := range set {
+			res[v] = res[v] && !set[v]
+		}
+	}
+	return res, unit{}, nil
+}
+
+// Returns set of bytes matched by difference, operands which are not
+// character sets are reported as errors
+func (c *converter) difference(units []unit) (*byteSet, error) {
+	set, operand, err := c.differenceSet(units, map[string]bool{})
+	if err != nil || set != nil {
+		return set, err
+	}
+
+	pos := units[0].node.Pos()
+	return nil, c.Errorf(pos, "difference %s can't be desugared: %s is not " +
+	                          "a character set",
+	                     c.Src[pos:units[len(units) - 1].end()],
+	                     c.Src[operand.node.Pos():operand.end()])
+}
+
+// Returns symbol matching any byte of set, text of set at offset pos is used
+// in error of empty set
+func (c *converter) setSymbol(set byteSet, pos int,
+                              text string) (bnf.Symbol, error) {
+	var alternatives bnf.Substitution
+	for v := range set {
+		if !set[v] {
+			continue
+		}
+		alternatives.Sequences = append(alternatives.Sequences, bnf.Sequence{
+			Symbols: []bnf.Symbol{
+				bnf.SymbolTerminal{Name: string([]byte{byte(v)})},
+			},
+		})
+	}
+
+	switch len(alternatives.Sequences) {
+	case 0:
+		return nil, c.Errorf(pos, "%s matches no characters", text)
+	case 1:
+		return alternatives.Sequences[0].Symbols[0], nil
+	}
+	return bnf.SymbolGroup{Alternatives: alternatives}, nil
+}
+
+// Splits units of text into rules
+func (c *converter) collectRules(units []unit) error {
+	for i := 0; i < len(units); {
+		head := units[i]
+		name := c.Text(head.node)
+		if _, ok := c.index[name]; ok {
+			return c.Errorf(head.node.Pos(), "rule %s is already defined",
+			                name)
+		}
+
+		i++
+		start := i
+		for i < len(units) && units[i].op != "::=" {
+			i++
+		}
+
+		if i == start {
+			return c.Errorf(head.node.Pos(), "rule %s is empty", name)
+		}
+
+		r := rule{head: head, body: units[start:i]}
+		r.end = r.body[len(r.body) - 1].end()
+
+		c.index[name] = len(c.rules)
+		c.rules = append(c.rules, r)
+	}
+	return nil
+}
+
+// Returns grammar of W3C EBNF text
+func Parse(src string) (*bnf.Grammar, error) {
+	err := loadMeta()
+	if err != nil {
+		return nil, err
+	}
+
+	tree, _, err := meta.syntax.Parser.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	c := converter{
+		Source: notation.NewSource(meta.syntax, src),
+		index: map[string]int{},
+	}
+
+	err = c.collectRules(c.units(tree))
+	if err != nil {
+		return nil, err
+	}
+
+	var g bnf.Grammar
+	for _, r := range c.rules {
+		alternatives, err := c.expression(r.body, r.end)
+		if err != nil {
+			return nil, err
+		}
+		g.Rules = append(g.Rules, bnf.Rule{
+			Head: bnf.SymbolNonTerminal{Name: c.Text(r.head.node)},
+			Tail: alternatives,
+		})
+	}
+
+	for _, ref := range c.refs {
+		if _, ok := c.index[c.Text(ref)]; !ok {
+			return nil, c.Errorf(ref.Pos(), "rule %s is not defined",
+			                     c.Text(ref))
+		}
+	}
+	return &g, nil
+}
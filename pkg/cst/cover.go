@@ -0,0 +1,61 @@
+package cst
+
+import (
+	"sort"
+)
+
+// Returns child of node containing [start:end) or nil.
+//
+// Childs spans produced by parser are sorted and contiguous, so child which
+// may contain range is the first one ending after start
+func coveringChild(node Node, start int, end int) Node {
+	childs := node.Childs()
+	i := sort.Search(len(childs), func(i int) bool {
+		return childs[i].End() > start
+	})
+	if i == len(childs) {
+		return nil
+	}
+
+	child := childs[i]
+	if child.Pos() > start || child.End() < end {
+		return nil
+	}
+	return child
+}
+
+func coveringPath(root Node, start int, end int) []Node {
+	if root.Pos() > start || root.End() < end || root.End() <= start {
+		return nil
+	}
+
+	path := []Node{root}
+	node := root
+	for {
+		node = coveringChild(node, start, end)
+		if node == nil {
+			return path
+		}
+		path = append(path, node)
+	}
+}
+
+// Returns path from root to the innermost node containing byte at offset,
+// nil if offset is out of root's span. Zero length nodes contain nothing so
+// never end up in path
+func NodeAt(root Node, offset int) []Node {
+	return coveringPath(root, offset, offset + 1)
+}
+
+// Returns path from root to the innermost node containing whole [start:end)
+// range, nil if range is not within root's span. Empty range is treated as
+// range of byte at start, or of the last byte if start is at root's end
+func NodesCovering(root Node, start int, end int) []Node {
+	if end <= start {
+		if start == root.End() && start > root.Pos() {
+			start--
+		}
+		end = start + 1
+	}
+	return coveringPath(root, start, end)
+}
@@ -43,12 +43,24 @@ type Visitor interface {
 	Visit(node Node) (w Visitor)
 }
 
+// Visits node and it's childs depth-first with visitors returned by Visit,
+// childs are skipped when it returns nil. Nil node is visited as itself
 func Walk(v Visitor, node Node) {
-	w := v.Visit(node)
-	if w == nil {
+	if node == nil {
+		v.Visit(nil)
 		return
 	}
-	for _, child := range node.Childs() {
-		Walk(w, child)
+
+	it := NewIterator(node)
+	// visitors[i] visits nodes at depth i
+	visitors := []Visitor{v}
+	for it.Next() {
+		visitors = visitors[:it.Depth() + 1]
+		w := visitors[it.Depth()].Visit(it.Node())
+		if w == nil {
+			it.SkipChildren()
+			continue
+		}
+		visitors = append(visitors, w)
 	}
 }
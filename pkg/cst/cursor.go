@@ -0,0 +1,100 @@
+package cst
+
+// Cursor for navigating tree in any direction.
+//
+// Node knows only it's childs, so cursor keeps path from root to current node
+// and extends it as it goes, nothing is computed upfront.
+type TreeCursor struct {
+	// nodes from root to current node
+	path []Node
+	// index of path[i] in path[i-1]'s childs, indexes[0] is unused
+	indexes []int
+}
+
+func NewTreeCursor(root Node) *TreeCursor {
+	return &TreeCursor{
+		path: []Node{root},
+		indexes: []int{0},
+	}
+}
+
+// Returns node cursor is at
+func (c *TreeCursor) Node() Node {
+	return c.path[len(c.path) - 1]
+}
+
+// Returns depth of current node, root is at depth 0
+func (c *TreeCursor) Depth() int {
+	return len(c.path) - 1
+}
+
+// Returns index of current node among it's parent's childs, 0 for root
+func (c *TreeCursor) Index() int {
+	return c.indexes[len(c.indexes) - 1]
+}
+
+// Returns independent copy of cursor at the same node
+func (c *TreeCursor) Copy() *TreeCursor {
+	return &TreeCursor{
+		path: append([]Node{}, c.path...),
+		indexes: append([]int{}, c.indexes...),
+	}
+}
+
+func (c *TreeCursor) parent() Node {
+	return c.path[len(c.path) - 2]
+}
+
+// Moves to parent. Returns false and stays in place if at root
+func (c *TreeCursor) GotoParent() bool {
+	if len(c.path) == 1 {
+		return false
+	}
+	c.path = c.path[:len(c.path) - 1]
+	c.indexes = c.indexes[:len(c.indexes) - 1]
+	return true
+}
+
+func (c *TreeCursor) gotoChild(i int) bool {
+	childs := c.Node().Childs()
+	if i < 0 || i >= len(childs) {
+		return false
+	}
+	c.path = append(c.path, childs[i])
+	c.indexes = append(c.indexes, i)
+	return true
+}
+
+// Moves to first child. Returns false and stays in place if node has none
+func (c *TreeCursor) GotoFirstChild() bool {
+	return c.gotoChild(0)
+}
+
+// Moves to last child. Returns false and stays in place if node has none
+func (c *TreeCursor) GotoLastChild() bool {
+	return c.gotoChild(len(c.Node().Childs()) - 1)
+}
+
+func (c *TreeCursor) gotoSibling(i int) bool {
+	if len(c.path) == 1 {
+		return false
+	}
+	siblings := c.parent().Childs()
+	if i < 0 || i >= len(siblings) {
+		return false
+	}
+	c.path[len(c.path) - 1] = siblings[i]
+	c.indexes[len(c.indexes) - 1] = i
+	return true
+}
+
+// Moves to next sibling. Returns false and stays in place if there is none
+func (c *TreeCursor) GotoNextSibling() bool {
+	return c.gotoSibling(c.Index() + 1)
+}
+
+// Moves to previous sibling. Returns false and stays in place if there is
+// none
+func (c *TreeCursor) GotoPrevSibling() bool {
+	return c.gotoSibling(c.Index() - 1)
+}
@@ -0,0 +1,178 @@
+package cst
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Options of Equal and Diff. Zero value compares types, spans and childs of
+// every node
+type CompareOptions struct {
+	// Do not compare node spans, only tree shape and types
+	IgnoreSpans bool
+	// Nodes of these types are skipped as if they were not in tree.
+	// LiteralTypes gives types of _literal and _nothing nodes
+	IgnoreTypes []int
+}
+
+// Returns types of _literal and _nothing nodes in naming map names
+func LiteralTypes(names map[int]string) []int {
+	var res []int
+	for t, name := range names {
+		if name == "_literal" || name == "_nothing" {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+// Texts longer than this are cut in Diff output
+const diffTextLimit = 32
+
+type comparer struct {
+	opts *CompareOptions
+	ignored map[int]bool
+
+	// when false comparer stops on first difference and does not describe it
+	collect bool
+	names map[int]string
+	aStr string
+	bStr string
+
+	diffs []string
+}
+
+func newComparer(opts *CompareOptions) *comparer {
+	c := comparer{
+		opts: opts,
+		ignored: make(map[int]bool, len(opts.IgnoreTypes)),
+	}
+	for _, t := range opts.IgnoreTypes {
+		c.ignored[t] = true
+	}
+	return &c
+}
+
+func (c *comparer) nodeTypeName(nodeType int) string {
+	val, ok := c.names[nodeType]
+	if !ok {
+		return fmt.Sprintf("Unknown_%d", nodeType)
+	}
+	return val
+}
+
+// Returns node's type name followed by quoted text it spans in str
+func (c *comparer) describe(node Node, str string) string {
+	name := c.nodeTypeName(node.Type())
+	if node.Pos() < 0 || node.End() > len(str) || node.Pos() > node.End() {
+		return fmt.Sprintf("%s [%d:%d]", name, node.Pos(), node.End())
+	}
+
+	text := str[node.Pos():node.End()]
+	if len(text) > diffTextLimit {
+		return name + " " + strconv.Quote(text[:diffTextLimit]) + "..."
+	}
+	return name + " " + strconv.Quote(text)
+}
+
+func (c *comparer) differ(path string, format string, a ...any) {
+	if c.collect {
+		c.diffs = append(c.diffs, path + ": " + fmt.Sprintf(format, a...))
+		return
+	}
+	c.diffs = append(c.diffs, "")
+}
+
+func (c *comparer) done() bool {
+	return !c.collect && len(c.diffs) > 0
+}
+
+func (c *comparer) childs(node Node) []Node {
+	if len(c.ignored) == 0 {
+		return node.Childs()
+	}
+
+	var res []Node
+	for _, child := range node.Childs() {
+		if !c.ignored[child.Type()] {
+			res = append(res, child)
+		}
+	}
+	return res
+}
+
+func (c *comparer) childPath(path string, child Node, i int) string {
+	if !c.collect {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s[%d]", path, c.nodeTypeName(child.Type()), i)
+}
+
+func (c *comparer) compare(path string, a Node, b Node) {
+	if a.Type() != b.Type() {
+		c.differ(path, "%s != %s",
+		         c.describe(a, c.aStr), c.describe(b, c.bStr))
+		return
+	}
+
+	if !c.opts.IgnoreSpans && (a.Pos() != b.Pos() || a.End() != b.End()) {
+		c.differ(path, "span [%d:%d] != [%d:%d]",
+		         a.Pos(), a.End(), b.Pos(), b.End())
+		if c.done() {
+			return
+		}
+	}
+
+	aChilds := c.childs(a)
+	bChilds := c.childs(b)
+	if len(aChilds) != len(bChilds) {
+		c.differ(path, "%d childs != %d childs", len(aChilds), len(bChilds))
+		if c.done() {
+			return
+		}
+	}
+
+	for i := 0; i < len(aChilds) || i < len(bChilds); i++ {
+		switch {
+		case i >= len(bChilds):
+			c.differ(c.childPath(path, aChilds[i], i), "only in a: %s",
+			         c.describe(aChilds[i], c.aStr))
+		case i >= len(aChilds):
+			c.differ(c.childPath(path, bChilds[i], i), "only in b: %s",
+			         c.describe(bChilds[i], c.bStr))
+		default:
+			c.compare(c.childPath(path, aChilds[i], i), aChilds[i], bChilds[i])
+		}
+		if c.done() {
+			return
+		}
+	}
+}
+
+// Reports whether trees with roots a and b are equal. Roots are compared
+// even if their types are ignored
+func Equal(a Node, b Node, opts CompareOptions) bool {
+	c := newComparer(&opts)
+	c.compare("", a, b)
+	return len(c.diffs) == 0
+}
+
+// Returns differences between trees with roots a and b, empty if trees are
+// Equal. Difference is reported as path to node from root followed by what
+// differs, e.g.
+//
+//     /pair[0]/item[2]: span [3:4] != [3:5]
+//
+// Path steps are type names with index among parent's (not ignored) childs.
+// names is naming map shared by trees, aStr and bStr are texts trees span
+func Diff(a Node, b Node, names map[int]string, aStr string, bStr string,
+          opts CompareOptions) []string {
+	c := newComparer(&opts)
+	c.collect = true
+	c.names = names
+	c.aStr = aStr
+	c.bStr = bStr
+
+	c.compare(c.childPath("", a, 0), a, b)
+	return c.diffs
+}
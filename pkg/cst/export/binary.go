@@ -0,0 +1,226 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/TooManySugar/ll1parser/pkg/cst"
+)
+
+// Binary document:
+//
+//     magic       "LL1CST" followed by format version byte
+//     checksum    SHA-256 of source text, 32 bytes
+//     names       uvarint count, then per name: varint type, uvarint length
+//                 and name bytes
+//     root        node
+//
+// Node is stored as varint type, varint start relative to end of previous
+// sibling (start of parent for first child, 0 for root), uvarint length and
+// uvarint count of childs followed by childs
+const (
+	binaryMagic   = "LL1CST"
+	binaryVersion = 1
+)
+
+// Returned by ReadBinary when tree was written for different source
+var ErrSourceMismatch = errors.New("tree was built from different source")
+
+type binaryWriter struct {
+	w *bufio.Writer
+	buf [binary.MaxVarintLen64]byte
+}
+
+func (bw *binaryWriter) uvarint(x int) {
+	n := binary.PutUvarint(bw.buf[:], uint64(x))
+	bw.w.Write(bw.buf[:n])
+}
+
+func (bw *binaryWriter) varint(x int) {
+	n := binary.PutVarint(bw.buf[:], int64(x))
+	bw.w.Write(bw.buf[:n])
+}
+
+func (bw *binaryWriter) node(node cst.Node, base int) {
+	bw.varint(node.Type())
+	bw.varint(node.Pos() - base)
+	bw.uvarint(node.End() - node.Pos())
+
+	childs := node.Childs()
+	bw.uvarint(len(childs))
+	base = node.Pos()
+	for _, child := range childs {
+		bw.node(child, base)
+		base = child.End()
+	}
+}
+
+// Writes tree to w in compact binary form together with names and checksum
+// of src, so ReadBinary can tell whether tree still matches the source
+func WriteBinary(w io.Writer, root cst.Node, names map[int]string,
+                 src string) error {
+	bw := binaryWriter{w: bufio.NewWriter(w)}
+
+	bw.w.WriteString(binaryMagic)
+	bw.w.WriteByte(binaryVersion)
+	sum := sha256.Sum256([]byte(src))
+	bw.w.Write(sum[:])
+
+	all := usedNames(root, names)
+	for t, name := range names {
+		all[t] = name
+	}
+	types := make([]int, 0, len(all))
+	for t := range all {
+		types = append(types, t)
+	}
+	sort.Ints(types)
+
+	bw.uvarint(len(types))
+	for _, t := range types {
+		bw.varint(t)
+		bw.uvarint(len(all[t]))
+		bw.w.WriteString(all[t])
+	}
+
+	bw.node(root, 0)
+
+	return bw.w.Flush()
+}
+
+type binaryReader struct {
+	r *bufio.Reader
+	// length of source, no node can span past it
+	srcLen int
+}
+
+func (br *binaryReader) uvarint() (int, error) {
+	x, err := binary.ReadUvarint(br.r)
+	if err != nil {
+		return 0, err
+	}
+	if x > uint64(br.srcLen) {
+		return 0, fmt.Errorf("value %d out of source length %d", x, br.srcLen)
+	}
+	return int(x), nil
+}
+
+func (br *binaryReader) varint() (int, error) {
+	x, err := binary.ReadVarint(br.r)
+	if err != nil {
+		return 0, err
+	}
+	return int(x), nil
+}
+
+func (br *binaryReader) node(base int,
+                             names map[int]string) (cst.Node, error) {
+	t, err := br.varint()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := names[t]; !ok {
+		return nil, fmt.Errorf("node type %d missing in names", t)
+	}
+
+	startDelta, err := br.varint()
+	if err != nil {
+		return nil, err
+	}
+	length, err := br.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	// checked before adding, so untrusted delta can't overflow
+	if startDelta < -base || startDelta > br.srcLen - base - length {
+		return nil, fmt.Errorf("node start %d%+d of length %d out of source " +
+		                       "length %d", base, startDelta, length,
+		                       br.srcLen)
+	}
+	start := base + startDelta
+	end := start + length
+
+	// childs are not preallocated as count is not trusted
+	count, err := binary.ReadUvarint(br.r)
+	if err != nil {
+		return nil, err
+	}
+
+	var childs []cst.Node
+	base = start
+	for i := uint64(0); i < count; i++ {
+		child, err := br.node(base, names)
+		if err != nil {
+			return nil, err
+		}
+		childs = append(childs, child)
+		base = child.End()
+	}
+
+	return cst.NewNode(t, start, end, childs), nil
+}
+
+// Reads tree written by WriteBinary for src. Returns tree and it's naming
+// map, or ErrSourceMismatch if tree was written for other source
+func ReadBinary(r io.Reader, src string) (cst.Node, map[int]string, error) {
+	br := binaryReader{
+		r: bufio.NewReader(r),
+		srcLen: len(src),
+	}
+
+	header := make([]byte, len(binaryMagic) + 1 + sha256.Size)
+	_, err := io.ReadFull(br.r, header)
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't read header: %w", err)
+	}
+	if string(header[:len(binaryMagic)]) != binaryMagic {
+		return nil, nil, fmt.Errorf("not a binary tree document")
+	}
+	if version := header[len(binaryMagic)]; version != binaryVersion {
+		return nil, nil, fmt.Errorf("unsupported format version %d", version)
+	}
+	sum := sha256.Sum256([]byte(src))
+	if !bytes.Equal(header[len(binaryMagic) + 1:], sum[:]) {
+		return nil, nil, ErrSourceMismatch
+	}
+
+	count, err := binary.ReadUvarint(br.r)
+	if err != nil {
+		return nil, nil, err
+	}
+	names := map[int]string{}
+	for i := uint64(0); i < count; i++ {
+		t, err := br.varint()
+		if err != nil {
+			return nil, nil, err
+		}
+		length, err := binary.ReadUvarint(br.r)
+		if err != nil {
+			return nil, nil, err
+		}
+		// not preallocated as length is not trusted
+		name := strings.Builder{}
+		_, err = io.CopyN(&name, br.r, int64(length))
+		if err != nil {
+			return nil, nil, err
+		}
+		names[t] = name.String()
+	}
+
+	root, err := br.node(0, names)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, nil, err
+	}
+
+	return root, names, nil
+}
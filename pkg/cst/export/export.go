@@ -0,0 +1,246 @@
+// Exporters of named parse trees to formats readable outside of Go:
+// JSON (with matching decoder), S-expressions and Graphviz DOT, and to
+// compact binary form for caching parse results
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/TooManySugar/ll1parser/pkg/cst"
+)
+
+func nodeTypeName(names map[int]string, nodeType int) string {
+	val, ok := names[nodeType]
+	if !ok {
+		return fmt.Sprintf("Unknown_%d", nodeType)
+	}
+	return val
+}
+
+func isLeaf(node cst.Node) bool {
+	return len(node.Childs()) == 0
+}
+
+// JSON document:
+//
+//     {
+//       "names": {"0": "pair", "1": "item", "-1": "_literal"},
+//       "root": {
+//         "type": "pair", "start": 0, "end": 3,
+//         "children": [
+//           {"type": "_literal", "start": 0, "end": 1, "text": "a"},
+//           ...
+//         ]
+//       }
+//     }
+//
+// names holds every type used in tree, text is present only for leaves
+type jsonDocument struct {
+	Names map[int]string `json:"names"`
+	Root  *jsonNode      `json:"root"`
+}
+
+type jsonNode struct {
+	Type     string      `json:"type"`
+	Start    int         `json:"start"`
+	End      int         `json:"end"`
+	Text     *string     `json:"text,omitempty"`
+	Children []*jsonNode `json:"children,omitempty"`
+}
+
+// Collects names of all types used in tree
+func usedNames(root cst.Node, names map[int]string) map[int]string {
+	res := map[int]string{}
+	var collect func(node cst.Node)
+	collect = func(node cst.Node) {
+		res[node.Type()] = nodeTypeName(names, node.Type())
+		for _, child := range node.Childs() {
+			collect(child)
+		}
+	}
+	collect(root)
+	return res
+}
+
+func writeJSONNode(w *bufio.Writer, node cst.Node,
+                   names map[int]string, src string) {
+	name, _ := json.Marshal(nodeTypeName(names, node.Type()))
+	fmt.Fprintf(w, `{"type":%s,"start":%d,"end":%d`,
+	            name, node.Pos(), node.End())
+
+	if isLeaf(node) {
+		text, _ := json.Marshal(src[node.Pos():node.End()])
+		fmt.Fprintf(w, `,"text":%s}`, text)
+		return
+	}
+
+	w.WriteString(`,"children":[`)
+	for i, child := range node.Childs() {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		writeJSONNode(w, child, names, src)
+	}
+	w.WriteString(`]}`)
+}
+
+// Writes tree to w as JSON document
+func WriteJSON(w io.Writer, root cst.Node, names map[int]string,
+               src string) error {
+	bw := bufio.NewWriter(w)
+
+	used := usedNames(root, names)
+	types := make([]int, 0, len(used))
+	for t := range used {
+		types = append(types, t)
+	}
+	sort.Ints(types)
+
+	bw.WriteString(`{"names":{`)
+	for i, t := range types {
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		name, _ := json.Marshal(used[t])
+		fmt.Fprintf(bw, `"%d":%s`, t, name)
+	}
+	bw.WriteString(`},"root":`)
+	writeJSONNode(bw, root, names, src)
+	bw.WriteString("}\n")
+
+	return bw.Flush()
+}
+
+func (n *jsonNode) toNode(types map[string]int) (cst.Node, error) {
+	t, ok := types[n.Type]
+	if !ok {
+		return nil, fmt.Errorf("node type `%s` missing in names", n.Type)
+	}
+
+	if n.Start > n.End {
+		return nil, fmt.Errorf("node `%s` ends before start: [%d:%d]",
+		                       n.Type, n.Start, n.End)
+	}
+
+	if len(n.Children) == 0 {
+		return cst.NewNode(t, n.Start, n.End, nil), nil
+	}
+
+	childs := make([]cst.Node, len(n.Children))
+	for i, child := range n.Children {
+		node, err := child.toNode(types)
+		if err != nil {
+			return nil, err
+		}
+		childs[i] = node
+	}
+
+	return cst.NewNode(t, n.Start, n.End, childs), nil
+}
+
+// Reads tree written by WriteJSON. Returns tree and it's naming map
+func ReadJSON(r io.Reader) (cst.Node, map[int]string, error) {
+	var doc jsonDocument
+	err := json.NewDecoder(r).Decode(&doc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if doc.Root == nil {
+		return nil, nil, fmt.Errorf("no root node in JSON document")
+	}
+
+	types := make(map[string]int, len(doc.Names))
+	for t, name := range doc.Names {
+		if other, ok := types[name]; ok {
+			return nil, nil, fmt.Errorf("types %d and %d share name `%s`",
+			                            other, t, name)
+		}
+		types[name] = t
+	}
+
+	root, err := doc.Root.toNode(types)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return root, doc.Names, nil
+}
+
+func writeSExprNode(w *bufio.Writer, node cst.Node,
+                    names map[int]string, src string) {
+	w.WriteByte('(')
+	w.WriteString(nodeTypeName(names, node.Type()))
+
+	if isLeaf(node) {
+		w.WriteByte(' ')
+		w.WriteString(strconv.Quote(src[node.Pos():node.End()]))
+		w.WriteByte(')')
+		return
+	}
+
+	for _, child := range node.Childs() {
+		w.WriteByte(' ')
+		writeSExprNode(w, child, names, src)
+	}
+	w.WriteByte(')')
+}
+
+// Writes tree to w as single line S-expression: inner node is (name childs...)
+// and leaf is (name "text")
+func WriteSExpr(w io.Writer, root cst.Node, names map[int]string,
+                src string) error {
+	bw := bufio.NewWriter(w)
+	writeSExprNode(bw, root, names, src)
+	bw.WriteByte('\n')
+	return bw.Flush()
+}
+
+// Escapes string to be put in double quoted DOT ID
+func dotEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return s
+}
+
+// Writes tree to w as Graphviz DOT digraph. Nodes are labeled with type name
+// and span, leaves also with their text
+func WriteDOT(w io.Writer, root cst.Node, names map[int]string,
+              src string) error {
+	bw := bufio.NewWriter(w)
+
+	bw.WriteString("digraph cst {\n")
+	bw.WriteString("\tnode [shape=box];\n")
+
+	id := 0
+	var write func(node cst.Node) int
+	write = func(node cst.Node) int {
+		nodeId := id
+		id++
+
+		label := fmt.Sprintf("%s [%d:%d]",
+		                     nodeTypeName(names, node.Type()),
+		                     node.Pos(), node.End())
+		if isLeaf(node) {
+			label += "\n" + strconv.Quote(src[node.Pos():node.End()])
+		}
+		label = strings.ReplaceAll(dotEscape(label), "\n", `\n`)
+		fmt.Fprintf(bw, "\tn%d [label=\"%s\"];\n", nodeId, label)
+
+		for _, child := range node.Childs() {
+			childId := write(child)
+			fmt.Fprintf(bw, "\tn%d -> n%d;\n", nodeId, childId)
+		}
+		return nodeId
+	}
+	write(root)
+
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
@@ -0,0 +1,74 @@
+package cst
+
+type iteratorFrame struct {
+	node Node
+	// index of node's child to visit next
+	next int
+}
+
+// Depth-first pre-order iterator over tree. Unlike Walk it keeps path to
+// current node on heap, so trees as deep as input is long are fine:
+//
+//     it := cst.NewIterator(root)
+//     for it.Next() {
+//         if skip(it.Node()) {
+//             it.SkipChildren()
+//         }
+//     }
+type Iterator struct {
+	root Node
+	// path from root to current node
+	stack []iteratorFrame
+	started bool
+}
+
+func NewIterator(root Node) *Iterator {
+	return &Iterator{root: root}
+}
+
+// Moves to next node. Returns false when there are no more nodes
+func (it *Iterator) Next() bool {
+	if !it.started {
+		it.started = true
+		if it.root == nil {
+			return false
+		}
+		it.stack = append(it.stack, iteratorFrame{node: it.root})
+		return true
+	}
+
+	for len(it.stack) > 0 {
+		top := &it.stack[len(it.stack) - 1]
+		childs := top.node.Childs()
+		if top.next < len(childs) {
+			child := childs[top.next]
+			top.next++
+			it.stack = append(it.stack, iteratorFrame{node: child})
+			return true
+		}
+		it.stack = it.stack[:len(it.stack) - 1]
+	}
+	return false
+}
+
+// Returns current node, nil before first and after last call to Next
+func (it *Iterator) Node() Node {
+	if len(it.stack) == 0 {
+		return nil
+	}
+	return it.stack[len(it.stack) - 1].node
+}
+
+// Returns depth of current node, root is at depth 0
+func (it *Iterator) Depth() int {
+	return len(it.stack) - 1
+}
+
+// Makes Next not to descend into current node's childs
+func (it *Iterator) SkipChildren() {
+	if len(it.stack) == 0 {
+		return
+	}
+	top := &it.stack[len(it.stack) - 1]
+	top.next = len(top.node.Childs())
+}
@@ -0,0 +1,143 @@
+package cst
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Options of Fprint. Zero value prints every node indented by two spaces per
+// level as <name>: string(<text>)
+type PrintOptions struct {
+	// Print only nodes on first MaxDepth levels, 0 means no limit
+	MaxDepth int
+	// Do not print _literal and _nothing nodes
+	HideLiterals bool
+	// Print byte span of node as [start:end]
+	ShowSpans bool
+	// Print position of node as line:col-line:col, both 1 based
+	ShowLineCol bool
+	// Print text quoted with Go escape sequences
+	Quote bool
+	// Print node per line without indentation prefixed with it's depth.
+	// Text is always quoted and span is always shown, suited for golden files
+	Compact bool
+}
+
+// Offsets of line starts for offset to line:col conversion
+type lineIndex []int
+
+func newLineIndex(src string) lineIndex {
+	res := lineIndex{0}
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			res = append(res, i + 1)
+		}
+	}
+	return res
+}
+
+// Returns 1 based line and byte column of offset
+func (li lineIndex) position(offset int) (line int, col int) {
+	i := sort.SearchInts(li, offset + 1) - 1
+	return i + 1, offset - li[i] + 1
+}
+
+type printVisitor struct {
+	level int
+	opts *PrintOptions
+	names map[int]string
+	str string
+	lines lineIndex
+	w *bufio.Writer
+}
+
+func (v printVisitor) nodeTypeName(nodeType int) string {
+	val, ok := v.names[nodeType]
+	if !ok {
+		return fmt.Sprintf("Unknown_%d", nodeType)
+	}
+	return val
+}
+
+func (v printVisitor) Visit(node Node) (w Visitor) {
+	if node == nil {
+		return nil
+	}
+
+	if v.opts.MaxDepth > 0 && v.level >= v.opts.MaxDepth {
+		return nil
+	}
+
+	name := v.nodeTypeName(node.Type())
+	if v.opts.HideLiterals && (name == "_literal" || name == "_nothing") {
+		return nil
+	}
+
+	text := v.str[node.Pos():node.End()]
+
+	if v.opts.Compact {
+		fmt.Fprintf(v.w, "%d %s %d:%d", v.level, name, node.Pos(), node.End())
+	} else {
+		fmt.Fprintf(v.w, "%s%s", strings.Repeat("  ", v.level), name)
+		if v.opts.ShowSpans {
+			fmt.Fprintf(v.w, " [%d:%d]", node.Pos(), node.End())
+		}
+	}
+
+	if v.opts.ShowLineCol {
+		startLine, startCol := v.lines.position(node.Pos())
+		endLine, endCol := v.lines.position(node.End())
+		fmt.Fprintf(v.w, " %d:%d-%d:%d", startLine, startCol, endLine, endCol)
+	}
+
+	switch {
+	case v.opts.Compact:
+		fmt.Fprintf(v.w, " %q\n", text)
+	case v.opts.Quote:
+		fmt.Fprintf(v.w, ": %q\n", text)
+	default:
+		fmt.Fprintf(v.w, ": string(%s)\n", text)
+	}
+
+	res := v
+	res.level++
+	return res
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += n
+	return n, err
+}
+
+// Writes tree with root to w naming nodes with names and showing text of
+// str they span. Returns number of bytes written and first write error
+func Fprint(w io.Writer, root Node, names map[int]string, str string,
+            opts PrintOptions) (int, error) {
+
+	cw := countingWriter{w: w}
+	v := printVisitor{
+		level: 0,
+		opts: &opts,
+		names: names,
+		str: str,
+		w: bufio.NewWriter(&cw),
+	}
+
+	if opts.ShowLineCol {
+		v.lines = newLineIndex(str)
+	}
+
+	Walk(v, root)
+
+	err := v.w.Flush()
+	return cw.n, err
+}
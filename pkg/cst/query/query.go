@@ -0,0 +1,386 @@
+// Selector language for finding nodes of named parse trees.
+//
+//     object > key-value > string     string childs of key-value childs of object
+//     array value                     value nodes anywhere inside array
+//     //value[0]                      every value which is first value child
+//     /json > element                 element childs of root which is json
+//     key-value@kv > value@v          value childs of key-value, both captured
+//
+// Query is a chain of steps separated with combinators:
+//
+//     >            right step is child of the left one
+//     // or space  right step is descendant of the left one
+//
+// Step is rule name (or * for any node) optionally followed by [n] - node
+// must be n-th (0 based) among it's parent's childs matching the step, and by
+// @name - matched node is captured under the name.
+//
+// Query starting with / must match from the root, otherwise first step can
+// match any node.
+package query
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/TooManySugar/ll1parser/pkg/cst"
+)
+
+// Possible combinators between steps
+const (
+	combinatorChild int = iota
+	combinatorDescendant
+)
+
+type step struct {
+	// rule name, empty for *
+	name string
+	// required position among parent's childs matching step, -1 if any
+	index int
+	capture string
+	// combinator to the previous step
+	combinator int
+}
+
+type Query struct {
+	steps []step
+	// first step must match the root
+	anchored bool
+}
+
+// Matched node with nodes captured on the way to it
+type Match struct {
+	Node cst.Node
+	Captures map[string]cst.Node
+}
+
+func isNameChar(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') ||
+	       ('0' <= c && c <= '9') || c == '-' || c == '_'
+}
+
+type queryScanner struct {
+	src string
+	offset int
+}
+
+func (s *queryScanner) peek() byte {
+	if s.offset >= len(s.src) {
+		return byte(0)
+	}
+	return s.src[s.offset]
+}
+
+// Skips whitespace and returns whether any was skipped
+func (s *queryScanner) skipSpaces() bool {
+	start := s.offset
+	for s.peek() == ' ' || s.peek() == '\t' || s.peek() == '\n' {
+		s.offset++
+	}
+	return s.offset > start
+}
+
+func (s *queryScanner) skip(prefix string) bool {
+	if len(s.src) - s.offset < len(prefix) ||
+	   s.src[s.offset:s.offset + len(prefix)] != prefix {
+		return false
+	}
+	s.offset += len(prefix)
+	return true
+}
+
+func (s *queryScanner) name() string {
+	start := s.offset
+	for isNameChar(s.peek()) {
+		s.offset++
+	}
+	return s.src[start:s.offset]
+}
+
+func (s *queryScanner) errorf(format string, a ...any) error {
+	return fmt.Errorf("%s at %d in query `%s`",
+	                  fmt.Sprintf(format, a...), s.offset, s.src)
+}
+
+func (s *queryScanner) unexpected() error {
+	if s.peek() == byte(0) {
+		return s.errorf("unexpected end")
+	}
+	return s.errorf("unexpected `%c`", s.peek())
+}
+
+func (s *queryScanner) step() (step, error) {
+	res := step{index: -1}
+
+	if !s.skip("*") {
+		res.name = s.name()
+		if res.name == "" {
+			return res, s.unexpected()
+		}
+	}
+
+	if s.skip("[") {
+		start := s.offset
+		for '0' <= s.peek() && s.peek() <= '9' {
+			s.offset++
+		}
+		index, err := strconv.Atoi(s.src[start:s.offset])
+		if err != nil {
+			return res, s.unexpected()
+		}
+		res.index = index
+		if !s.skip("]") {
+			return res, s.unexpected()
+		}
+	}
+
+	if s.skip("@") {
+		res.capture = s.name()
+		if res.capture == "" {
+			return res, s.unexpected()
+		}
+	}
+
+	return res, nil
+}
+
+// Compiles query text to Query
+func Compile(src string) (*Query, error) {
+	var res Query
+	s := queryScanner{src: src}
+
+	s.skipSpaces()
+	if s.skip("//") {
+		s.skipSpaces()
+	} else if s.skip("/") {
+		res.anchored = true
+		s.skipSpaces()
+	}
+
+	// first step can be anywhere below the (virtual) parent of root
+	combinator := combinatorDescendant
+	for {
+		st, err := s.step()
+		if err != nil {
+			return nil, err
+		}
+		st.combinator = combinator
+		res.steps = append(res.steps, st)
+
+		spaced := s.skipSpaces()
+		if s.peek() == byte(0) {
+			return &res, nil
+		}
+
+		switch {
+		case s.skip(">"):
+			combinator = combinatorChild
+		case s.skip("//"):
+			combinator = combinatorDescendant
+		case spaced:
+			combinator = combinatorDescendant
+		default:
+			return nil, s.unexpected()
+		}
+		s.skipSpaces()
+	}
+}
+
+// Like Compile but panics on error
+func MustCompile(src string) *Query {
+	q, err := Compile(src)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// Node on the path from root to currently visited node
+type pathEntry struct {
+	node cst.Node
+	// position among parent's childs of the same type
+	typeIndex int
+	// position among all parent's childs
+	anyIndex int
+}
+
+type matcher struct {
+	q *Query
+	types map[string]int
+	path []pathEntry
+	matches []Match
+
+	// failed[pathIndex * len(steps) + stepIndex] is set when matchAt is known
+	// to fail for the current path
+	failed []bool
+	// chain[stepIndex] is path index matched by step in last successful
+	// matchAt
+	chain []int
+
+	// counts of childs per type shifted by minType, zero between uses
+	counts []int
+	minType int
+	// type indexes of childs per path depth, reused between siblings
+	typeIndexes [][]int
+}
+
+func (m *matcher) stepMatches(st step, e pathEntry) bool {
+	if st.name == "" {
+		return st.index < 0 || e.anyIndex == st.index
+	}
+
+	t, ok := m.types[st.name]
+	if !ok || e.node.Type() != t {
+		return false
+	}
+	return st.index < 0 || e.typeIndex == st.index
+}
+
+// Checks whether steps up to stepIndex match path ending at pathIndex with
+// step stepIndex matched by path[pathIndex]. On success fills chain
+func (m *matcher) matchAt(stepIndex int, pathIndex int) bool {
+	failed := &m.failed[pathIndex * len(m.q.steps) + stepIndex]
+	if *failed {
+		return false
+	}
+	if !m.matchStepAt(stepIndex, pathIndex) {
+		*failed = true
+		return false
+	}
+	m.chain[stepIndex] = pathIndex
+	return true
+}
+
+func (m *matcher) matchStepAt(stepIndex int, pathIndex int) bool {
+	st := m.q.steps[stepIndex]
+	if !m.stepMatches(st, m.path[pathIndex]) {
+		return false
+	}
+
+	switch {
+	case stepIndex == 0:
+		return !m.q.anchored || pathIndex == 0
+
+	case st.combinator == combinatorChild:
+		return pathIndex > 0 && m.matchAt(stepIndex - 1, pathIndex - 1)
+	}
+
+	for i := pathIndex - 1; i >= 0; i-- {
+		if m.matchAt(stepIndex - 1, i) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns captures of steps matched by last successful matchAt
+func (m *matcher) captures() map[string]cst.Node {
+	res := map[string]cst.Node{}
+	for i, st := range m.q.steps {
+		if st.capture != "" {
+			res[st.capture] = m.path[m.chain[i]].node
+		}
+	}
+	return res
+}
+
+// Returns positions of childs among parent's childs of the same type
+func (m *matcher) childTypeIndexes(childs []cst.Node) []int {
+	depth := len(m.path) - 1
+	for len(m.typeIndexes) <= depth {
+		m.typeIndexes = append(m.typeIndexes, nil)
+	}
+	res := m.typeIndexes[depth][:0]
+
+	inRange := true
+	for _, child := range childs {
+		t := child.Type() - m.minType
+		if t < 0 || t >= len(m.counts) {
+			inRange = false
+			break
+		}
+	}
+
+	if inRange {
+		for _, child := range childs {
+			t := child.Type() - m.minType
+			res = append(res, m.counts[t])
+			m.counts[t]++
+		}
+		for _, child := range childs {
+			m.counts[child.Type() - m.minType] = 0
+		}
+	} else {
+		counts := map[int]int{}
+		for _, child := range childs {
+			res = append(res, counts[child.Type()])
+			counts[child.Type()]++
+		}
+	}
+
+	m.typeIndexes[depth] = res
+	return res
+}
+
+func (m *matcher) visit(e pathEntry) {
+	m.path = append(m.path, e)
+
+	pathIndex := len(m.path) - 1
+	for range m.q.steps {
+		m.failed = append(m.failed, false)
+	}
+
+	if m.matchAt(len(m.q.steps) - 1, pathIndex) {
+		m.matches = append(m.matches, Match{
+			Node: e.node,
+			Captures: m.captures(),
+		})
+	}
+
+	childs := e.node.Childs()
+	typeIndexes := m.childTypeIndexes(childs)
+	for i, child := range childs {
+		m.visit(pathEntry{
+			node: child,
+			typeIndex: typeIndexes[i],
+			anyIndex: i,
+		})
+	}
+
+	m.failed = m.failed[:pathIndex * len(m.q.steps)]
+	m.path = m.path[:pathIndex]
+}
+
+// Returns matches of query in tree with root in document order. names is
+// tree's naming map
+func (q *Query) MatchCaptures(root cst.Node, names map[int]string) []Match {
+	m := matcher{
+		q: q,
+		types: make(map[string]int, len(names)),
+		chain: make([]int, len(q.steps)),
+	}
+	maxType := -1
+	for t, name := range names {
+		m.types[name] = t
+		if t < m.minType {
+			m.minType = t
+		}
+		if t > maxType {
+			maxType = t
+		}
+	}
+	m.counts = make([]int, maxType - m.minType + 1)
+
+	m.visit(pathEntry{node: root})
+	return m.matches
+}
+
+// Returns nodes matched by query in tree with root in document order
+func (q *Query) Match(root cst.Node, names map[int]string) []cst.Node {
+	matches := q.MatchCaptures(root, names)
+	res := make([]cst.Node, len(matches))
+	for i := range matches {
+		res[i] = matches[i].Node
+	}
+	return res
+}
@@ -0,0 +1,200 @@
+// Mutable parse trees for editing sources.
+//
+// Tree built with New can be changed in place: subtrees replaced with new
+// text, childs inserted and removed. Text of changed tree keeps every byte
+// of untouched nodes and bytes between them as they were in source:
+//
+//     root := rewrite.New(res.Root, string(res.Src))
+//     root.Childs()[1].Replace("b")
+//     src := root.Text()
+//     tree := root.ToNode()   // spans of tree are in src
+package rewrite
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/TooManySugar/ll1parser/pkg/cst"
+)
+
+type Node struct {
+	typei int
+	// text of node before childs, only leaf nodes have it
+	text string
+	// source bytes before each child not covered by previous childs
+	leads []string
+	// source bytes after last child
+	trail string
+	childs []*Node
+	parent *Node
+}
+
+func newNode(node cst.Node, src string, parent *Node) *Node {
+	res := &Node{
+		typei: node.Type(),
+		parent: parent,
+	}
+
+	childs := node.Childs()
+	if len(childs) == 0 {
+		res.text = src[node.Pos():node.End()]
+		return res
+	}
+
+	res.childs = make([]*Node, len(childs))
+	res.leads = make([]string, len(childs))
+	pos := node.Pos()
+	for i, child := range childs {
+		if child.Pos() > pos {
+			res.leads[i] = src[pos:child.Pos()]
+		}
+		res.childs[i] = newNode(child, src, res)
+		if child.End() > pos {
+			pos = child.End()
+		}
+	}
+	if node.End() > pos {
+		res.trail = src[pos:node.End()]
+	}
+
+	return res
+}
+
+// Returns mutable copy of tree with root, src is text tree was parsed from
+func New(root cst.Node, src string) *Node {
+	return newNode(root, src, nil)
+}
+
+// Returns new node of type nType without childs spanning text
+func NewLeaf(nType int, text string) *Node {
+	return &Node{
+		typei: nType,
+		text: text,
+	}
+}
+
+// Returns new node of type nType with childs. Childs must not be in other
+// tree
+func NewNode(nType int, childs []*Node) (*Node, error) {
+	res := &Node{typei: nType}
+	for _, child := range childs {
+		err := res.InsertChild(len(res.childs), child)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (n *Node) Type() int {
+	return n.typei
+}
+
+// Returns parent of node, nil for root
+func (n *Node) Parent() *Node {
+	return n.parent
+}
+
+// Returns childs of node. Slice must not be modified, use InsertChild and
+// RemoveChild instead
+func (n *Node) Childs() []*Node {
+	return n.childs
+}
+
+// Replaces subtree with node by leaf spanning text. Node keeps it's type and
+// place in tree
+func (n *Node) Replace(text string) {
+	for _, child := range n.childs {
+		child.parent = nil
+	}
+	n.childs = nil
+	n.leads = nil
+	n.trail = ""
+	n.text = text
+}
+
+// Inserts child before i-th child of node, i == len(Childs()) appends it.
+// Child must not be in other tree
+func (n *Node) InsertChild(i int, child *Node) error {
+	if i < 0 || i > len(n.childs) {
+		return fmt.Errorf("child index %d out of range [0:%d]",
+		                  i, len(n.childs))
+	}
+	if child.parent != nil {
+		return fmt.Errorf("inserted node already has parent")
+	}
+
+	if len(n.childs) == 0 {
+		// leaf becomes inner node and it's own text is lost
+		n.text = ""
+	}
+
+	n.childs = append(n.childs, nil)
+	copy(n.childs[i + 1:], n.childs[i:])
+	n.childs[i] = child
+
+	n.leads = append(n.leads, "")
+	copy(n.leads[i + 1:], n.leads[i:])
+	n.leads[i] = ""
+
+	child.parent = n
+	return nil
+}
+
+// Removes i-th child of node together with source bytes between it and
+// previous child. Returns removed child, which then can be inserted elsewhere
+func (n *Node) RemoveChild(i int) (*Node, error) {
+	if i < 0 || i >= len(n.childs) {
+		return nil, fmt.Errorf("child index %d out of range [0:%d)",
+		                       i, len(n.childs))
+	}
+
+	child := n.childs[i]
+	n.childs = append(n.childs[:i], n.childs[i + 1:]...)
+	n.leads = append(n.leads[:i], n.leads[i + 1:]...)
+
+	child.parent = nil
+	return child, nil
+}
+
+func (n *Node) writeTo(sb *strings.Builder) {
+	sb.WriteString(n.text)
+	for i, child := range n.childs {
+		sb.WriteString(n.leads[i])
+		child.writeTo(sb)
+	}
+	sb.WriteString(n.trail)
+}
+
+// Returns text of subtree with node
+func (n *Node) Text() string {
+	sb := strings.Builder{}
+	n.writeTo(&sb)
+	return sb.String()
+}
+
+// Returns immutable tree with spans of node's subtree text starting at
+// offset and offset right after it's end
+func (n *Node) toNode(offset int) (cst.Node, int) {
+	start := offset
+	offset += len(n.text)
+
+	var childs []cst.Node
+	if len(n.childs) > 0 {
+		childs = make([]cst.Node, len(n.childs))
+	}
+	for i, child := range n.childs {
+		offset += len(n.leads[i])
+		childs[i], offset = child.toNode(offset)
+	}
+	offset += len(n.trail)
+
+	return cst.NewNode(n.typei, start, offset, childs), offset
+}
+
+// Returns immutable copy of subtree with node with spans recomputed to match
+// it's Text
+func (n *Node) ToNode() cst.Node {
+	res, _ := n.toNode(0)
+	return res
+}
@@ -0,0 +1,187 @@
+// Simplifying transforms of parse trees.
+//
+// LL(1) grammars force right recursive tails and explicit empty alternatives
+// so resulting trees are deep spines with _nothing leaves:
+//
+//     text
+//       character
+//       text
+//         character
+//         text
+//           _nothing
+//
+// With Config{Flatten: []string{"text"}, DropNothing: true} it becomes:
+//
+//     text
+//       character
+//       character
+package transform
+
+import (
+	"fmt"
+
+	"github.com/TooManySugar/ll1parser/pkg/bnf"
+	"github.com/TooManySugar/ll1parser/pkg/cst"
+)
+
+// Names of builtin node types as set by parser
+const (
+	literalName = "_literal"
+	nothingName = "_nothing"
+)
+
+type Config struct {
+	// Rules which nodes get childs of the same rule spliced in place of
+	// those childs, turning recursive chains into sibling lists
+	Flatten []string
+
+	// Remove _nothing nodes
+	DropNothing bool
+
+	// Remove _literal nodes with one of these texts
+	DropLiterals []string
+
+	// Rules which nodes having single child are replaced with that child
+	Collapse []string
+
+	// Splice nodes of rules generated by bnf.Desugar in place of them
+	// without their _nothing childs, so repetitions and groups become plain
+	// childs of rule they are written in
+	InlineGenerated bool
+}
+
+type transformer struct {
+	flatten  map[int]bool
+	collapse map[int]bool
+	inline   map[int]bool
+	dropLiterals map[string]bool
+	dropNothing  bool
+
+	literalType int
+	nothingType int
+	hasLiteral bool
+	hasNothing bool
+
+	src string
+}
+
+func typesOf(ruleNames []string, types map[string]int) (map[int]bool, error) {
+	res := make(map[int]bool, len(ruleNames))
+	for _, name := range ruleNames {
+		t, ok := types[name]
+		if !ok {
+			return nil, fmt.Errorf("no rule named <%s>", name)
+		}
+		res[t] = true
+	}
+	return res, nil
+}
+
+func newTransformer(names map[int]string, src string,
+                    cfg Config) (*transformer, error) {
+	types := make(map[string]int, len(names))
+	for t, name := range names {
+		types[name] = t
+	}
+
+	var tr transformer
+	var err error
+
+	tr.flatten, err = typesOf(cfg.Flatten, types)
+	if err != nil {
+		return nil, err
+	}
+
+	tr.collapse, err = typesOf(cfg.Collapse, types)
+	if err != nil {
+		return nil, err
+	}
+
+	tr.dropLiterals = make(map[string]bool, len(cfg.DropLiterals))
+	for _, text := range cfg.DropLiterals {
+		tr.dropLiterals[text] = true
+	}
+	tr.dropNothing = cfg.DropNothing
+
+	tr.inline = map[int]bool{}
+	if cfg.InlineGenerated {
+		for t, name := range names {
+			if bnf.IsGeneratedName(name) {
+				tr.inline[t] = true
+			}
+		}
+	}
+
+	tr.literalType, tr.hasLiteral = types[literalName]
+	tr.nothingType, tr.hasNothing = types[nothingName]
+
+	tr.src = src
+
+	return &tr, nil
+}
+
+func (tr *transformer) isDropped(node cst.Node) bool {
+	if tr.dropNothing && tr.hasNothing && node.Type() == tr.nothingType {
+		return true
+	}
+
+	if len(tr.dropLiterals) > 0 && tr.hasLiteral &&
+	   node.Type() == tr.literalType {
+		return tr.dropLiterals[tr.src[node.Pos():node.End()]]
+	}
+
+	return false
+}
+
+// Transforms tree bottom up: childs are transformed first, then dropped
+// childs are removed, then generated and same rule childs are spliced, then
+// node itself is collapsed
+func (tr *transformer) transform(node cst.Node) cst.Node {
+	oldChilds := node.Childs()
+	if len(oldChilds) == 0 {
+		return node
+	}
+
+	var childs []cst.Node
+	for _, child := range oldChilds {
+		if tr.isDropped(child) {
+			continue
+		}
+
+		child = tr.transform(child)
+
+		if tr.inline[child.Type()] {
+			for _, grandChild := range child.Childs() {
+				if !tr.hasNothing || grandChild.Type() != tr.nothingType {
+					childs = append(childs, grandChild)
+				}
+			}
+			continue
+		}
+
+		if tr.flatten[node.Type()] && child.Type() == node.Type() {
+			childs = append(childs, child.Childs()...)
+			continue
+		}
+
+		childs = append(childs, child)
+	}
+
+	if tr.collapse[node.Type()] && len(childs) == 1 {
+		return childs[0]
+	}
+
+	return cst.NewNode(node.Type(), node.Pos(), node.End(), childs)
+}
+
+// Returns transformed copy of tree with root. names is tree's naming map and
+// src is text tree was parsed from
+func Apply(root cst.Node, names map[int]string, src string,
+           cfg Config) (cst.Node, error) {
+	tr, err := newTransformer(names, src, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return tr.transform(root), nil
+}
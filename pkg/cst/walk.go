@@ -0,0 +1,99 @@
+package cst
+
+import (
+	"errors"
+)
+
+// Returned by Traverse's enter function to not descend into node's childs.
+// Exit function is still called for the node
+var SkipChildren = errors.New("skip childs")
+
+// Returned by Traverse's functions to stop traversal, Traverse then returns
+// nil
+var SkipAll = errors.New("skip all")
+
+// Traverses tree depth-first calling enter for node before it's childs and
+// exit after them. Either function can be nil.
+//
+// enter may return SkipChildren to skip node's childs, any function may
+// return SkipAll to stop traversal. Any other error stops traversal and is
+// returned by Traverse
+func Traverse(root Node, enter func(Node) error, exit func(Node) error) error {
+	if enter == nil {
+		enter = func(Node) error { return nil }
+	}
+
+	err := traverse(root, enter, exit)
+	if err == SkipAll {
+		return nil
+	}
+	return err
+}
+
+func traverse(root Node, enter func(Node) error, exit func(Node) error) error {
+	it := NewIterator(root)
+	// entered nodes not exited yet, entered[i] is at depth i
+	var entered []Node
+
+	// exits entered nodes deeper than depth, innermost first
+	exitTo := func(depth int) error {
+		for len(entered) > depth {
+			node := entered[len(entered) - 1]
+			entered = entered[:len(entered) - 1]
+			if exit == nil {
+				continue
+			}
+			err := exit(node)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for it.Next() {
+		err := exitTo(it.Depth())
+		if err != nil {
+			return err
+		}
+
+		entered = append(entered, it.Node())
+		err = enter(it.Node())
+		switch err {
+		case nil:
+		case SkipChildren:
+			it.SkipChildren()
+		default:
+			return err
+		}
+	}
+
+	return exitTo(0)
+}
+
+// Traverses tree depth-first like go/ast.Inspect: calls f(node) and if it
+// returns true visits node's childs followed by call f(nil)
+func Inspect(root Node, f func(Node) bool) {
+	// set when f returned false: exit comes right after such enter and must
+	// not call f(nil)
+	skipped := false
+
+	enter := func(node Node) error {
+		if !f(node) {
+			skipped = true
+			return SkipChildren
+		}
+		return nil
+	}
+
+	exit := func(node Node) error {
+		if skipped {
+			skipped = false
+			return nil
+		}
+		f(nil)
+		return nil
+	}
+
+	Traverse(root, enter, exit)
+}
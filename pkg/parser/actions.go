@@ -0,0 +1,131 @@
+package parser
+
+import (
+	"fmt"
+)
+
+// Semantic action called when rule is reduced.
+//
+// childs holds values of rule's sequence symbols in order: terminals' values
+// are their text, non terminals' values are whatever was returned for them.
+// For empty sequence ("") childs is empty. start and end are span of rule
+// in source.
+//
+// Returned value becomes rule's value, returned error stops parsing.
+type ReduceFunc func(childs []any, start int, end int) (any, error)
+
+// Value of rule without action:
+// value of single child as is, nil for no childs, otherwise childs themselves
+func defaultReduce(childs []any) any {
+	switch len(childs) {
+	case 0:
+		return nil
+	case 1:
+		return childs[0]
+	}
+	return childs
+}
+
+type ll1parserValueStack struct {
+	stack []any
+}
+
+func (valueStack *ll1parserValueStack) Pop() (any, bool) {
+	stackLen := len(valueStack.stack)
+
+	if stackLen == 0 {
+		return nil, false
+	}
+
+	res := valueStack.stack[stackLen - 1]
+	valueStack.stack = valueStack.stack[:stackLen - 1]
+	return res, true
+}
+
+func (valueStack *ll1parserValueStack) Push(value any) {
+	valueStack.stack = append(valueStack.stack, value)
+}
+
+func (valueStack ll1parserValueStack) Len() int {
+	return len(valueStack.stack)
+}
+
+// processFunction counterpart for parsing with actions
+func (p *realParser) reduceFunction(f function_t) error {
+	name := f.Name()
+	amount := f.Amount()
+
+	childs := make([]any, amount)
+	for i := amount - 1; i >= 0; i-- {
+		value, ok := p.valueStack.Pop()
+		if !ok {
+			panic("trying to pop from empty stack")
+		}
+		childs[i] = value
+	}
+
+	if name < 0 {
+		// builtins are always reduced to their text
+		p.valueStack.Push(string(p.scanner.src[f.Pos():p.scanner.aPos()]))
+		return nil
+	}
+
+	action, ok := p.actions[name]
+	if !ok {
+		p.valueStack.Push(defaultReduce(childs))
+		return nil
+	}
+
+	value, err := action(childs, f.Pos(), p.scanner.aPos())
+	if err != nil {
+		return err
+	}
+
+	p.valueStack.Push(value)
+	return nil
+}
+
+// Parses src reducing every rule to a value with action registered for
+// rule's name instead of building parse tree. Returns value of start rule
+func ParseValue(p LL1Parser,
+                src any,
+                actions map[string]ReduceFunc) (any, error) {
+	lp, err := ll1parserOf(p)
+	if err != nil {
+		return nil, err
+	}
+	return lp.parseValue(src, actions)
+}
+
+func (p ll1parser_t) parseValue(src any,
+                                actions map[string]ReduceFunc) (any, error) {
+
+	if err := p.checkTable(); err != nil {
+		return nil, err
+	}
+
+	text, err := readSource(src)
+	if err != nil {
+		return nil, err
+	}
+
+	rp := p.newRealParser(text)
+	rp.actions = make(map[int]ReduceFunc, len(actions))
+	for name, action := range actions {
+		t, ok := p.symbols.TypeOf(name)
+		if !ok || t < 0 {
+			return nil, fmt.Errorf("no rule named <%s> to bind action to", name)
+		}
+		if action == nil {
+			return nil, fmt.Errorf("action of rule <%s> is nil", name)
+		}
+		rp.actions[t] = action
+	}
+
+	_, _, err = rp.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	return rp.value, nil
+}
@@ -0,0 +1,142 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Error in input found while parsing
+type SyntaxError struct {
+	// Offset in source where error occurred
+	Offset int
+	Msg string
+	// Names of rules being parsed at the moment of error, outermost first
+	Rules []string
+}
+
+func (e *SyntaxError) Error() string {
+	return e.Msg
+}
+
+// ANSI escape sequences used by ErrorPrinter
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiRed   = "\x1b[1;31m"
+	ansiGreen = "\x1b[1;32m"
+	ansiFaint = "\x1b[2m"
+)
+
+// Prints parsing errors with position, offending source line and `^` marker
+// under error column:
+//
+//     input.txt:1:3: error: expected char ':' (58), got '!' (33)
+//      1 | A:!=C
+//        |   ^
+//     in <Expr>
+type ErrorPrinter struct {
+	// Name of source shown before position, omitted if empty
+	Filename string
+	// Colorize output with ANSI escape sequences
+	Color bool
+	// Do not print rule stack
+	HideRules bool
+}
+
+func (ep ErrorPrinter) paint(s string, color string) string {
+	if !ep.Color {
+		return s
+	}
+	return color + s + ansiReset
+}
+
+// Returns 1 based line and byte column of offset in src with bounds of line
+// containing it (not including line break)
+func lineOf(src []byte, offset int) (line int, col int,
+                                     lineStart int, lineEnd int) {
+	if offset > len(src) {
+		offset = len(src)
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	lineStart = bytes.LastIndexByte(src[:offset], '\n') + 1
+	line = bytes.Count(src[:lineStart], []byte{'\n'}) + 1
+	col = offset - lineStart + 1
+
+	lineEnd = bytes.IndexByte(src[lineStart:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(src)
+	} else {
+		lineEnd += lineStart
+	}
+	if lineEnd > lineStart && src[lineEnd - 1] == '\r' {
+		lineEnd--
+	}
+
+	return line, col, lineStart, lineEnd
+}
+
+// Writes err rendered against src to w. Errors other than SyntaxError are
+// printed without source snippet
+func (ep ErrorPrinter) Fprint(w io.Writer, err error, src []byte) error {
+	sb := strings.Builder{}
+
+	if ep.Filename != "" {
+		sb.WriteString(ep.paint(ep.Filename + ":", ansiBold))
+	}
+
+	var se *SyntaxError
+	if !errors.As(err, &se) {
+		if ep.Filename != "" {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(ep.paint("error:", ansiRed))
+		sb.WriteByte(' ')
+		sb.WriteString(err.Error())
+		sb.WriteByte('\n')
+		_, werr := io.WriteString(w, sb.String())
+		return werr
+	}
+
+	line, col, lineStart, lineEnd := lineOf(src, se.Offset)
+
+	sb.WriteString(ep.paint(fmt.Sprintf("%d:%d:", line, col), ansiBold))
+	sb.WriteByte(' ')
+	sb.WriteString(ep.paint("error:", ansiRed))
+	sb.WriteByte(' ')
+	sb.WriteString(se.Msg)
+	sb.WriteByte('\n')
+
+	lineNo := fmt.Sprint(line)
+	gutter := strings.Repeat(" ", len(lineNo))
+
+	sb.WriteString(ep.paint(" " + lineNo + " | ", ansiFaint))
+	sb.Write(src[lineStart:lineEnd])
+	sb.WriteByte('\n')
+
+	// keep tabs so marker stays under the column whatever tab width is
+	sb.WriteString(ep.paint(" " + gutter + " | ", ansiFaint))
+	for _, c := range src[lineStart:lineStart + col - 1] {
+		if c == '\t' {
+			sb.WriteByte('\t')
+			continue
+		}
+		sb.WriteByte(' ')
+	}
+	sb.WriteString(ep.paint("^", ansiGreen))
+	sb.WriteByte('\n')
+
+	if !ep.HideRules && len(se.Rules) > 0 {
+		sb.WriteString("in <")
+		sb.WriteString(strings.Join(se.Rules, "> > <"))
+		sb.WriteString(">\n")
+	}
+
+	_, werr := io.WriteString(w, sb.String())
+	return werr
+}
@@ -0,0 +1,183 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/TooManySugar/ll1parser/pkg/cst"
+)
+
+// Text edit: Deleted bytes starting at Offset are replaced with Inserted
+type Edit struct {
+	Offset int
+	Deleted int
+	Inserted string
+}
+
+func (e Edit) apply(src []byte) ([]byte, error) {
+	if e.Offset < 0 || e.Deleted < 0 || e.Offset + e.Deleted > len(src) {
+		return nil, fmt.Errorf("edit [%d:%d] out of source bounds [0:%d]",
+		                       e.Offset, e.Offset + e.Deleted, len(src))
+	}
+
+	res := make([]byte, 0, len(src) - e.Deleted + len(e.Inserted))
+	res = append(res, src[:e.Offset]...)
+	res = append(res, e.Inserted...)
+	res = append(res, src[e.Offset + e.Deleted:]...)
+	return res, nil
+}
+
+// Returns indexes of childs from root to the deepest rule node which span
+// encloses edit and is unaffected on it's borders, i.e. it starts before edit
+// and ends not earlier than deleted text does.
+//
+// Everything parsed before such node depends only on input before edit and
+// everything parsed after it only on input after edit, so it is enough to
+// reparse the node alone.
+func editEnclosingPath(root cst.Node, e Edit) []int {
+	var path []int
+	node := root
+	for {
+		found := false
+		for i, child := range node.Childs() {
+			if child.Type() < 0 {
+				continue
+			}
+			if child.Pos() < e.Offset && e.Offset + e.Deleted <= child.End() {
+				path = append(path, i)
+				node = child
+				found = true
+				break
+			}
+		}
+		if !found {
+			return path
+		}
+	}
+}
+
+// View of node with all spans moved by delta. Childs are wrapped when they
+// are requested, so moving subtree after edit doesn't copy it
+type shiftedNode struct {
+	node cst.Node
+	delta int
+}
+
+func (n shiftedNode) Type() int {
+	return n.node.Type()
+}
+
+func (n shiftedNode) Pos() int {
+	return n.node.Pos() + n.delta
+}
+
+func (n shiftedNode) End() int {
+	return n.node.End() + n.delta
+}
+
+func (n shiftedNode) Childs() []cst.Node {
+	childs := n.node.Childs()
+	if childs == nil {
+		return nil
+	}
+
+	res := make([]cst.Node, len(childs))
+	for i, child := range childs {
+		res[i] = shiftedNode{node: child, delta: n.delta}
+	}
+	return res
+}
+
+// Returns node with all spans moved by delta, node moved by previous edits
+// is unwrapped, so views don't nest
+func shiftNode(node cst.Node, delta int) cst.Node {
+	if delta == 0 {
+		return node
+	}
+
+	if shifted, ok := node.(shiftedNode); ok {
+		if shifted.delta + delta == 0 {
+			return shifted.node
+		}
+		return shiftedNode{node: shifted.node, delta: shifted.delta + delta}
+	}
+	return shiftedNode{node: node, delta: delta}
+}
+
+// Returns copy of root where node at path replaced with repl and nodes after
+// it moved by delta
+func spliceNode(root cst.Node, path []int, repl cst.Node, delta int) cst.Node {
+	if len(path) == 0 {
+		return repl
+	}
+
+	at := path[0]
+	oldChilds := root.Childs()
+	childs := make([]cst.Node, len(oldChilds))
+
+	copy(childs, oldChilds[:at])
+	childs[at] = spliceNode(oldChilds[at], path[1:], repl, delta)
+	for i := at + 1; i < len(oldChilds); i++ {
+		childs[i] = shiftNode(oldChilds[i], delta)
+	}
+
+	return cst.NewNode(root.Type(), root.Pos(), root.End() + delta, childs)
+}
+
+// Parses source of prev with edit applied reusing subtrees of prev
+// unaffected by edit. Resulting tree is the same as full parse of edited
+// source would produce
+func Reparse(p LL1Parser, prev *ParseResult, edit Edit) (*ParseResult, error) {
+	lp, err := ll1parserOf(p)
+	if err != nil {
+		return nil, err
+	}
+	return lp.reparse(prev, edit)
+}
+
+func (p ll1parser_t) reparse(prev *ParseResult, e Edit) (*ParseResult, error) {
+
+	if err := p.checkTable(); err != nil {
+		return nil, err
+	}
+
+	text, err := e.apply(prev.Src)
+	if err != nil {
+		return nil, err
+	}
+
+	delta := len(e.Inserted) - e.Deleted
+
+	path := editEnclosingPath(prev.Root, e)
+
+	nodes := make([]cst.Node, len(path))
+	node := prev.Root
+	for i, at := range path {
+		node = node.Childs()[at]
+		nodes[i] = node
+	}
+
+	// Try from the innermost node outwards. Node can be reused only if it's
+	// reparsed version ends exactly where old one does, otherwise input
+	// after it would be read differently.
+	for i := len(nodes) - 1; i >= 0; i-- {
+		old := nodes[i]
+
+		rp := p.newRealParser(text)
+		rp.mode = parseModeSubtree
+		rp.startRule = old.Type()
+		rp.scanner.offset = old.Pos()
+
+		repl, _, err := rp.parse()
+		if err != nil || rp.scanner.aPos() != old.End() + delta {
+			continue
+		}
+
+		return &ParseResult{
+			Root: spliceNode(prev.Root, path[:i + 1], repl, delta),
+			Src: text,
+			symbols: p.symbols,
+		}, nil
+	}
+
+	return p.parseResult(text)
+}
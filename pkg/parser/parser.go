@@ -16,6 +16,7 @@ const (
 	opTypeFunction
 	opTypeEOS
 	opTypeChar
+	opTypeTerminalFold
 )
 
 // builin terminal types
@@ -28,6 +29,7 @@ const (
 // make it expose to be readable from table generator(s)
 // but keep lowercase in enum for consistency within
 const BuiltinTerminal = builtinTerminal
+const BuiltinNothing = builtinNothing
 
 // Parser operands inheritance work around
 type ParserOp interface {
@@ -68,6 +70,24 @@ func (t terminal_t) Value() string {
 }
 
 
+type terminalFold_t struct {
+	value string
+}
+
+// Terminal matching input ignoring case of ASCII letters
+func OpTerminalCaseInsensitive(s string) ParserOp {
+	return terminalFold_t{ value: s }
+}
+
+func (t terminalFold_t) parserOpType() int {
+	return opTypeTerminalFold
+}
+
+func (t terminalFold_t) Value() string {
+	return t.value
+}
+
+
 type function_t struct {
 	name int
 	pos int
@@ -113,6 +133,8 @@ func (e opEOS_t) parserOpType() int {
 
 type opChar_t struct {
 	value byte
+	// ignore case of ASCII letters
+	fold bool
 }
 
 func opChar(value byte) ParserOp {
@@ -121,6 +143,13 @@ func opChar(value byte) ParserOp {
 	}
 }
 
+func opCharFold(value byte) ParserOp {
+	return opChar_t{
+		value: value,
+		fold: true,
+	}
+}
+
 func (c opChar_t) parserOpType() int {
 	return opTypeChar
 }
@@ -129,6 +158,20 @@ func (c opChar_t) Value() byte {
 	return c.value
 }
 
+func toLowerASCII(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + ('a' - 'A')
+	}
+	return c
+}
+
+func (c opChar_t) Matches(input byte) bool {
+	if c.fold {
+		return toLowerASCII(c.value) == toLowerASCII(input)
+	}
+	return c.value == input
+}
+
 type ParserOpList []ParserOp
 
 func NewParserOpList(parserOps ...ParserOp) []ParserOp {
@@ -138,11 +181,16 @@ func NewParserOpList(parserOps ...ParserOp) []ParserOp {
 type ll1parser_t struct {
 	table map[int]map[byte][]ParserOp
 	names map[int]string
+	symbols *SymbolTable
 }
 
 func NewLL1Parser(table map[int]map[byte][]ParserOp,
 	              names map[int]string) LL1Parser {
-	return ll1parser_t{table: table, names: names}
+	return ll1parser_t{
+		table: table,
+		names: names,
+		symbols: newSymbolTable(names),
+	}
 }
 
 
@@ -199,12 +247,19 @@ func (prodStack ll1parserProdStack) Len() int {
 type ll1parserScanner struct {
 	src []byte
 	offset int
+	// src is only the beginning of input, more may follow
+	partial bool
+	// peek reached end of partial src
+	short bool
 	// lineOffset
 	// onLineOffset
 }
 
 func (s *ll1parserScanner) peek() byte {
 	if s.offset >= len(s.src) {
+		if s.partial {
+			s.short = true
+		}
 		return byte(0)
 	}
 	return s.src[s.offset]
@@ -228,29 +283,78 @@ type realParser struct {
 	scanner ll1parserScanner
 	opStack ll1parserOpStack
 	prodStack ll1parserProdStack
+	mode int
+	// rule parsing starts from
+	startRule int
+	// when set values are reduced with actions instead of building cst
+	actions map[int]ReduceFunc
+	valueStack ll1parserValueStack
+	value any
 }
 
-func (p *realParser) processTableNonTerminal(name int) error {
-	nodeTypeName := func (name_id int) string {
-		val, ok := p.names[name_id]
-		if !ok {
-			return fmt.Sprintf("Unknown_%d", name_id)
+// realParser modes
+const (
+	// whole input must be derived from start rule
+	parseModeFull int = iota
+	// longest prefix of input derivable from start rule is parsed
+	parseModePrefix
+	// single subtree of start rule is parsed from scanner's offset, input
+	// after it is only used as lookahead
+	parseModeSubtree
+)
+
+func (p *realParser) nodeTypeName(name_id int) string {
+	val, ok := p.names[name_id]
+	if !ok {
+		return fmt.Sprintf("Unknown_%d", name_id)
+	}
+	return val
+}
+
+// Returns names of rules currently being parsed, outermost first
+func (p *realParser) ruleStack() []string {
+	var res []string
+	for _, op := range p.opStack.stack {
+		f, ok := op.(function_t)
+		if !ok || f.Name() < 0 {
+			continue
 		}
-		return val
+		res = append(res, p.nodeTypeName(f.Name()))
+	}
+	return res
+}
+
+func (p *realParser) syntaxError(format string, a ...any) *SyntaxError {
+	return &SyntaxError{
+		Offset: p.scanner.aPos(),
+		Msg: fmt.Sprintf(format, a...),
+		Rules: p.ruleStack(),
 	}
+}
 
+func (p *realParser) processTableNonTerminal(name int) error {
 	ruleMap, ok := p.table[name]
 	if !ok {
 		// Table error
-		return fmt.Errorf("no rules for non terminal: %s", nodeTypeName(name))
+		err := p.syntaxError("no rules for non terminal: %s",
+		                     p.nodeTypeName(name))
+		err.Rules = append(err.Rules, p.nodeTypeName(name))
+		return err
 	}
 
 	opsToPush, ok := ruleMap[p.scanner.peek()]
+	if !ok && p.mode == parseModePrefix {
+		// nothing more from this rule can be matched with input: treat input
+		// as ended here
+		opsToPush, ok = ruleMap[byte(0)]
+	}
 	if !ok {
 		// Parsing error
-		return fmt.Errorf("no rules for %s and non terminal op <%s>",
-			           charCode(p.scanner.peek()),
-			           nodeTypeName(name))
+		err := p.syntaxError("no rules for %s and non terminal op <%s>",
+		                     charCode(p.scanner.peek()),
+		                     p.nodeTypeName(name))
+		err.Rules = append(err.Rules, p.nodeTypeName(name))
+		return err
 	}
 
 	p.opStack.Push(
@@ -282,8 +386,9 @@ func (p *realParser) processBuiltinNonTerminal(name int) error {
 			return nil
 		default:
 			// Parsing error
-			return fmt.Errorf("no rules for %s and builtin terminal op <EOL>",
-			                  charCode(p.scanner.peek()))
+			return p.syntaxError(
+				"no rules for %s and builtin terminal op <EOL>",
+				charCode(p.scanner.peek()))
 		}
 	}
 	return fmt.Errorf("unknown built in type: %d", name)
@@ -307,7 +412,21 @@ func (p *realParser) processTerminal(t terminal_t) {
 	}
 }
 
-func (p *realParser) processFunction(f function_t) {
+func (p *realParser) processTerminalFold(t terminalFold_t) {
+	tValue := t.Value()
+
+	p.opStack.Push(
+		opFunction(builtinTerminal, p.scanner.aPos(), len(tValue)))
+	for i := len(tValue) - 1; i >= 0; i -- {
+		p.opStack.Push(opCharFold(tValue[i]))
+	}
+}
+
+func (p *realParser) processFunction(f function_t) error {
+	if p.actions != nil {
+		return p.reduceFunction(f)
+	}
+
 	name := f.Name()
 	amount := f.Amount()
 	if amount == 0 {
@@ -320,7 +439,7 @@ func (p *realParser) processFunction(f function_t) {
 		                                     p.scanner.aPos(),
 		                                     nil),
 		                             }))
-		return
+		return nil
 	}
 
 	var childs []cst.Node
@@ -347,17 +466,27 @@ func (p *realParser) processFunction(f function_t) {
 	}
 
 	p.prodStack.Push(cst.NewNode(name, f.Pos(), p.scanner.aPos(), childs))
+	return nil
 }
 
 func (p *realParser) processEOS() (cst.Node, *map[int]string, error) {
-	if p.scanner.peek() != byte(0) {
+	if p.mode == parseModeFull && p.scanner.peek() != byte(0) {
 		// fmt.Println("expected end of input got: ", in.Peek())
 		// Parsing error Unexpected EOF
-		return nil, nil, fmt.Errorf("expected end of input got %s",
-									charCode(p.scanner.peek()))
+		return nil, nil, p.syntaxError("expected end of input got %s",
+		                               charCode(p.scanner.peek()))
 	}
 	// fmt.Println("Parsed successfully")
 
+	if p.actions != nil {
+		v, ok := p.valueStack.Pop()
+		if !ok {
+			return nil, nil, fmt.Errorf("value stack empty")
+		}
+		p.value = v
+		return nil, nil, nil
+	}
+
 	n, ok := p.prodStack.Pop()
 	if !ok {
 		// Table error too
@@ -372,11 +501,18 @@ func (p *realParser) processEOS() (cst.Node, *map[int]string, error) {
 }
 
 func (p *realParser) processChar(c opChar_t) error {
-	if c.Value() != p.scanner.peek() {
+	if !c.Matches(p.scanner.peek()) {
 		// Parsing error
-		return fmt.Errorf("expected char %s, got %s",
-		                  charCode(c.Value()),
-		                  charCode(p.scanner.peek()))
+		return p.syntaxError("expected char %s, got %s",
+		                     charCode(c.Value()),
+		                     charCode(p.scanner.peek()))
+	}
+
+	if p.actions != nil {
+		// char's value is taken by terminal as a whole
+		p.valueStack.Push(nil)
+		p.scanner.next()
+		return nil
 	}
 
 	p.prodStack.Push(
@@ -388,12 +524,24 @@ func (p *realParser) processChar(c opChar_t) error {
 	return nil
 }
 
-func (p realParser) parse() (cst.Node, *map[int]string, error) {
+func (p *realParser) parse() (cst.Node, *map[int]string, error) {
+	n, names, err := p.realParse()
+	if p.scanner.short {
+		return nil, nil, ErrShortInput
+	}
+	return n, names, err
+}
+
+func (p *realParser) realParse() (cst.Node, *map[int]string, error) {
 
 	p.opStack.Push(opEOS())
-	p.opStack.Push(OpNonTerminal(0))
+	p.opStack.Push(OpNonTerminal(p.startRule))
 
 	for p.opStack.Len() > 0 {
+		if p.scanner.short {
+			return nil, nil, ErrShortInput
+		}
+
 		// fmt.Println(opStack.stack, fmt.Sprintf("`%c`", in.Peek()))
 
 		op, _ := p.opStack.Pop()
@@ -420,13 +568,24 @@ func (p realParser) parse() (cst.Node, *map[int]string, error) {
 
 			p.processTerminal(t)
 		}
+		case opTypeTerminalFold: {
+			t, ok := (*op).(terminalFold_t)
+			if !ok {
+				panic("can't cast terminal op to it's type")
+			}
+
+			p.processTerminalFold(t)
+		}
 		case opTypeFunction: {
 			f, ok := (*op).(function_t)
 			if !ok {
 				panic("can't cast opTypeFunction op to it's type")
 			}
 
-			p.processFunction(f)
+			err := p.processFunction(f)
+			if err != nil {
+				return nil, nil, err
+			}
 		}
 		case opTypeEOS: {
 			return p.processEOS()
@@ -471,22 +630,19 @@ func readSource(src any) ([]byte, error) {
 	return nil, fmt.Errorf("invalid source")
 }
 
-func (p ll1parser_t) Parse(src any) (cst.Node, *map[int]string, error) {
-
+func (p ll1parser_t) checkTable() error {
 	if len(p.table) == 0 {
-		return nil, nil, fmt.Errorf("empty parsing table")
+		return fmt.Errorf("empty parsing table")
 	}
 
 	if _, found := p.table[0]; !found {
-		return nil, nil,
-			fmt.Errorf("can't start parsing: no rule for base entry point - 0")
+		return fmt.Errorf("can't start parsing: no rule for base entry point - 0")
 	}
 
-	text, err := readSource(src)
-	if err != nil {
-		return nil, nil, err
-	}
+	return nil
+}
 
+func (p ll1parser_t) newRealParser(text []byte) realParser {
 	var rp realParser
 	rp.table = p.table
 	rp.names = p.names
@@ -494,6 +650,99 @@ func (p ll1parser_t) Parse(src any) (cst.Node, *map[int]string, error) {
 		src: text,
 		offset: 0,
 	}
+	return rp
+}
+
+func (p ll1parser_t) Parse(src any) (cst.Node, *map[int]string, error) {
+
+	if err := p.checkTable(); err != nil {
+		return nil, nil, err
+	}
 
+	text, err := readSource(src)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rp := p.newRealParser(text)
 	return rp.parse()
 }
+
+// Returns parser created by NewLL1Parser behind p
+func ll1parserOf(p LL1Parser) (ll1parser_t, error) {
+	res, ok := p.(ll1parser_t)
+	if !ok {
+		return res, fmt.Errorf("parser %T is not created by NewLL1Parser", p)
+	}
+	return res, nil
+}
+
+// Parses longest prefix of src derivable from start rule, parsing stops
+// at first input character grammar can't continue with instead of
+// failing on it. Result's Src is the parsed prefix of src.
+// If atEOF is false and src ends before parsing is complete returns
+// ErrShortInput
+func ParsePrefix(p LL1Parser, src []byte, atEOF bool) (*ParseResult, error) {
+	lp, err := ll1parserOf(p)
+	if err != nil {
+		return nil, err
+	}
+	return lp.parsePrefix(src, atEOF)
+}
+
+func (p ll1parser_t) parsePrefix(src []byte,
+                                 atEOF bool) (*ParseResult, error) {
+
+	if err := p.checkTable(); err != nil {
+		return nil, err
+	}
+
+	rp := p.newRealParser(src)
+	rp.mode = parseModePrefix
+	rp.scanner.partial = !atEOF
+
+	root, _, err := rp.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ParseResult{
+		Root: root,
+		Src: src[:rp.scanner.aPos()],
+		symbols: p.symbols,
+	}, nil
+}
+
+// Same as p.Parse but returns tree together with parsed source and
+// symbol table in a single ParseResult
+func ParseToResult(p LL1Parser, src any) (*ParseResult, error) {
+	lp, err := ll1parserOf(p)
+	if err != nil {
+		return nil, err
+	}
+	return lp.parseResult(src)
+}
+
+func (p ll1parser_t) parseResult(src any) (*ParseResult, error) {
+
+	if err := p.checkTable(); err != nil {
+		return nil, err
+	}
+
+	text, err := readSource(src)
+	if err != nil {
+		return nil, err
+	}
+
+	rp := p.newRealParser(text)
+	root, _, err := rp.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ParseResult{
+		Root: root,
+		Src: text,
+		symbols: p.symbols,
+	}, nil
+}
@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/TooManySugar/ll1parser/pkg/cst"
+)
+
+// Read-only mapping between node types and rule names
+//
+// Built once from parser's naming map, never changes afterwards so it is safe
+// to share between results and goroutines
+type SymbolTable struct {
+	names map[int]string
+	types map[string]int
+}
+
+func newSymbolTable(names map[int]string) *SymbolTable {
+	st := SymbolTable{
+		names: make(map[int]string, len(names) + 2),
+		types: make(map[string]int, len(names) + 2),
+	}
+
+	for k, v := range names {
+		st.names[k] = v
+		st.types[v] = k
+	}
+
+	st.names[builtinTerminal] = "_literal"
+	st.names[builtinNothing]  = "_nothing"
+	st.types["_literal"] = builtinTerminal
+	st.types["_nothing"] = builtinNothing
+
+	return &st
+}
+
+// Returns name of node type or Unknown_<type> if type has no name
+func (st *SymbolTable) Name(nodeType int) string {
+	val, ok := st.names[nodeType]
+	if !ok {
+		return fmt.Sprintf("Unknown_%d", nodeType)
+	}
+	return val
+}
+
+// Returns node type of rule named name
+func (st *SymbolTable) TypeOf(name string) (int, bool) {
+	t, ok := st.types[name]
+	return t, ok
+}
+
+// Returns copy of underlying naming map in form returned by LL1Parser.Parse
+func (st *SymbolTable) Map() map[int]string {
+	res := make(map[int]string, len(st.names))
+	for k, v := range st.names {
+		res[k] = v
+	}
+	return res
+}
+
+// Parse tree bundled with everything needed to read it
+type ParseResult struct {
+	Root cst.Node
+	// Source parsed into Root, node spans are offsets in it
+	Src []byte
+
+	symbols *SymbolTable
+}
+
+func (r *ParseResult) Symbols() *SymbolTable {
+	return r.symbols
+}
+
+// Returns source text spanned by node
+func (r *ParseResult) Text(node cst.Node) string {
+	return string(r.Src[node.Pos():node.End()])
+}
+
+// Returns name of node's type
+func (r *ParseResult) Name(node cst.Node) string {
+	return r.symbols.Name(node.Type())
+}
+
+// Returns node type of rule named name
+func (r *ParseResult) TypeOf(name string) (int, bool) {
+	return r.symbols.TypeOf(name)
+}
@@ -0,0 +1,120 @@
+package parser
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// Returned by ParsePrefix when source ends before document does
+var ErrShortInput = errors.New("input ended before document is complete")
+
+// Returned when document of zero length is parsed from non empty input, this
+// would result in endless iteration over the same position
+var ErrEmptyDocument = errors.New("document of zero length")
+
+// Returns bufio.SplitFunc splitting stream into documents each derivable from
+// start rule of parser p.
+//
+// Every call parses data from it's beginning so document is parsed at least
+// once per buffer refill.
+func SplitFunc(p LL1Parser) bufio.SplitFunc {
+	split, _ := splitFunc(p)
+	return split
+}
+
+func splitFunc(p LL1Parser) (bufio.SplitFunc, **ParseResult) {
+	var last *ParseResult
+
+	split := func(data []byte, atEOF bool) (int, []byte, error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+
+		res, err := ParsePrefix(p, data, atEOF)
+		if err == ErrShortInput {
+			// request more data
+			return 0, nil, nil
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+
+		if len(res.Src) == 0 {
+			return 0, nil, ErrEmptyDocument
+		}
+
+		last = res
+		return len(res.Src), res.Src, nil
+	}
+
+	return split, &last
+}
+
+// Iterates over documents of stream parsing one after another with the same
+// parser. Interface follows bufio.Scanner:
+//
+//     s := parser.NewDocumentScanner(p, r)
+//     for s.Scan() {
+//         doc := s.Result()
+//         start, end := s.Range()
+//         ...
+//     }
+//     if s.Err() != nil {
+//         ...
+//     }
+type DocumentScanner struct {
+	scanner *bufio.Scanner
+	last **ParseResult
+	res *ParseResult
+	start int
+	end int
+}
+
+func NewDocumentScanner(p LL1Parser, r io.Reader) *DocumentScanner {
+	split, last := splitFunc(p)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Split(split)
+
+	return &DocumentScanner{
+		scanner: scanner,
+		last: last,
+	}
+}
+
+// Sets initial buffer and maximum document size as bufio.Scanner.Buffer does.
+// Must be called before first Scan
+func (s *DocumentScanner) Buffer(buf []byte, max int) {
+	s.scanner.Buffer(buf, max)
+}
+
+// Parses next document. Returns false when stream is over or on error
+func (s *DocumentScanner) Scan() bool {
+	if !s.scanner.Scan() {
+		s.res = nil
+		return false
+	}
+
+	s.res = *s.last
+	s.start = s.end
+	s.end += len(s.res.Src)
+	return true
+}
+
+// Returns last parsed document. Node spans are relative to document start,
+// not the stream. As with bufio.Scanner.Bytes result's Src may be overwritten
+// by subsequent call to Scan
+func (s *DocumentScanner) Result() *ParseResult {
+	return s.res
+}
+
+// Returns byte range of last parsed document within stream
+func (s *DocumentScanner) Range() (start int, end int) {
+	return s.start, s.end
+}
+
+// Returns first non-EOF error encountered
+func (s *DocumentScanner) Err() error {
+	return s.scanner.Err()
+}
@@ -0,0 +1,175 @@
+// Positions in sources of several files.
+//
+// Like go/token FileSet gives every registered file a range of global
+// positions, so offsets into different files (spans of trees from several
+// parses, error offsets) can be told apart and printed as file:line:col:
+//
+//     fset := token.NewFileSet()
+//     f := fset.AddFile("rules.bnf", src)
+//     tree, _, err := p.Parse(string(src))
+//     ...
+//     fmt.Println(fset.Position(f.Pos(node.Pos())))   // rules.bnf:12:7
+package token
+
+import (
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// Position in source file. Lines and columns are 1 based, zero value is
+// invalid position
+type Position struct {
+	Filename string
+	// byte offset in file
+	Offset int
+	Line int
+	// byte column
+	Column int
+	// column in UTF-16 code units as used by LSP and JavaScript
+	Column16 int
+}
+
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// Returns position as file:line:col, line:col if file has no name, or - if
+// position is invalid
+func (p Position) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+	if p.Filename == "" {
+		return fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.Filename, p.Line, p.Column)
+}
+
+type File struct {
+	name string
+	base int
+	src []byte
+	// offsets of line starts
+	lines []int
+}
+
+func newFile(name string, base int, src []byte) *File {
+	f := File{
+		name: name,
+		base: base,
+		src: src,
+		lines: []int{0},
+	}
+	for i, c := range src {
+		if c == '\n' {
+			f.lines = append(f.lines, i + 1)
+		}
+	}
+	return &f
+}
+
+func (f *File) Name() string {
+	return f.name
+}
+
+// Returns global position of file's first byte
+func (f *File) Base() int {
+	return f.base
+}
+
+func (f *File) Size() int {
+	return len(f.src)
+}
+
+func (f *File) LineCount() int {
+	return len(f.lines)
+}
+
+// Returns global position of offset in file
+func (f *File) Pos(offset int) int {
+	return f.base + offset
+}
+
+// Returns offset in file of global position
+func (f *File) Offset(pos int) int {
+	return pos - f.base
+}
+
+// Returns position of offset in file, invalid position if offset is out of
+// file. Offset equal to file size is position of file end
+func (f *File) Position(offset int) Position {
+	if offset < 0 || offset > len(f.src) {
+		return Position{}
+	}
+
+	i := sort.SearchInts(f.lines, offset + 1) - 1
+	lineStart := f.lines[i]
+
+	col16 := 1
+	for rest := f.src[lineStart:offset]; len(rest) > 0; {
+		r, size := utf8.DecodeRune(rest)
+		rest = rest[size:]
+		if r > 0xFFFF {
+			// encoded with surrogate pair
+			col16 += 2
+			continue
+		}
+		col16++
+	}
+
+	return Position{
+		Filename: f.name,
+		Offset: offset,
+		Line: i + 1,
+		Column: offset - lineStart + 1,
+		Column16: col16,
+	}
+}
+
+// Set of files sharing range of global positions
+type FileSet struct {
+	// files in order of base
+	files []*File
+	// base of next file
+	base int
+}
+
+func NewFileSet() *FileSet {
+	return &FileSet{}
+}
+
+// Registers file with name and content src. File gets positions right after
+// last added file, one extra position is reserved for file end so no two
+// files share position
+func (s *FileSet) AddFile(name string, src []byte) *File {
+	f := newFile(name, s.base, src)
+	s.files = append(s.files, f)
+	s.base += len(src) + 1
+	return f
+}
+
+// Returns files in order they were added
+func (s *FileSet) Files() []*File {
+	return s.files
+}
+
+// Returns file containing global position, nil if there is none
+func (s *FileSet) File(pos int) *File {
+	i := sort.Search(len(s.files), func(i int) bool {
+		return s.files[i].base + s.files[i].Size() >= pos
+	})
+	if i == len(s.files) || pos < s.files[i].base {
+		return nil
+	}
+	return s.files[i]
+}
+
+// Returns position of global position, invalid position if it is in no file
+func (s *FileSet) Position(pos int) Position {
+	f := s.File(pos)
+	if f == nil {
+		return Position{}
+	}
+	return f.Position(f.Offset(pos))
+}
@@ -95,14 +95,14 @@ func TestResolvedLRecursiveGrammarToParsingTableResTable(t *testing.T) {
 func TestBNFGrammarToParsingTableResNamingMap(t *testing.T) {
 	ref := map[int]string{
 		 0: "syntax",
-		 1: "more-lines",
-		 2: "line",
-		 3: "opt-content",
-		 4: "content",
-		 5: "expression",
-		 6: "expression-tail",
-		 7: "list",
-		 8: "list-tail",
+		 1: "items",
+		 2: "item",
+		 3: "rule-head",
+		 4: "ref-or-head",
+		 5: "group",
+		 6: "opt-define",
+		 7: "reference",
+		 8: "whitespace",
 		 9: "term",
 		10: "literal",
 		11: "text1",
@@ -120,6 +120,16 @@ func TestBNFGrammarToParsingTableResNamingMap(t *testing.T) {
 		23: "symbol",
 		24: "opt-whitespace",
 		25: "EOL",
+		26: "case-flag",
+		27: "factor",
+		28: "repeat-op",
+		29: "repeat",
+		30: "group-tail",
+		31: "comment",
+		32: "comment-text",
+		33: "comment-char",
+		34: "other-char",
+		35: "hex-digit",
 	}
 
 	grammar := bnf.SelfGrammar()
@@ -0,0 +1,74 @@
+package cst_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/TooManySugar/ll1parser/pkg/cst"
+	"github.com/TooManySugar/ll1parser/pkg/parser"
+	tc "github.com/TooManySugar/ll1parser/test/testcommon"
+)
+
+func pathString(res *parser.ParseResult, path []cst.Node) string {
+	var names []string
+	for _, node := range path {
+		names = append(names, res.Name(node))
+	}
+	return strings.Join(names, " > ")
+}
+
+func TestNodeAt(t *testing.T) {
+
+	p := tc.MustParser(t, objectGrammar)
+
+	// 0123456789012
+	// {a:1,b:{a:2}}
+	res, err := parser.ParseToResult(p, "{a:1,b:{a:2}}")
+	if err != nil {
+		t.Fatalf("Failed to parse input: %s", err.Error())
+	}
+
+	tests := []struct {
+		start int
+		end int
+		ref string
+	}{
+		{0, 0, "object > _literal"},
+		{1, 1, "object > members > key-value > key > _literal"},
+		{4, 4, "object > members > members-tail > _literal"},
+		{8, 11, "object > members > members-tail > key-value > value > " +
+		        "object > members > key-value"},
+		{6, 13, "object"},
+		{1, 3, "object > members > key-value"},
+		{14, 14, ""},
+		{-1, 2, ""},
+	}
+
+	for _, test := range tests {
+		path := cst.NodesCovering(res.Root, test.start, test.end)
+		if pathString(res, path) != test.ref {
+			t.Errorf("[%d:%d]: expected %s, got %s",
+			         test.start, test.end, test.ref, pathString(res, path))
+		}
+
+		if test.start != test.end {
+			continue
+		}
+		path = cst.NodeAt(res.Root, test.start)
+		if pathString(res, path) != test.ref {
+			t.Errorf("%d: expected %s, got %s",
+			         test.start, test.ref, pathString(res, path))
+		}
+	}
+
+	// empty range at end of source is range of it's last byte, while there
+	// is no byte at that offset
+	ref := "object > _literal"
+	path := cst.NodesCovering(res.Root, 13, 13)
+	if pathString(res, path) != ref {
+		t.Errorf("[13:13]: expected %s, got %s", ref, pathString(res, path))
+	}
+	if path = cst.NodeAt(res.Root, 13); path != nil {
+		t.Errorf("13: expected no path, got %s", pathString(res, path))
+	}
+}
@@ -4,59 +4,12 @@ import (
 	"testing"
 	"bytes"
 	"os"
-	"io"
-	"fmt"
-	"strings"
 
 	"github.com/TooManySugar/ll1parser/pkg/cst"
+	"github.com/TooManySugar/ll1parser/pkg/parser"
 	tc "github.com/TooManySugar/ll1parser/test/testcommon"
 )
 
-type namedVisitor struct {
-	level int
-	names map[int]string
-	sb *strings.Builder
-	str *string
-}
-
-func NewNamedVisitor(names map[int]string, str string) (v namedVisitor)  {
-	sb := strings.Builder{}
-	return namedVisitor{
-		level: 0,
-		names: names,
-		sb: &sb,
-		str: &str,
-	}
-}
-
-func (v namedVisitor) Visit(node cst.Node) (w cst.Visitor) {
-	if node == nil {
-		return nil
-	}
-
-	nodeTypeToName := func (name_id int) string {
-		val, ok := v.names[name_id]
-		if !ok {
-			return fmt.Sprintf("Unknown_%d", name_id)
-		}
-		return val
-	}
-	fmt.Fprintf(v.sb, "%s%s: string(%s)\n", strings.Repeat("  ", int(v.level)), nodeTypeToName(node.Type()), (*v.str)[node.Pos():node.End()])
-
-	return namedVisitor {
-		level: v.level + 1,
-		names: v.names,
-		sb: v.sb,
-		str: v.str,
-	}
-}
-
-func FprintTreeNamed(w io.Writer, root cst.Node, namingMap map[int]string, str string) (int, error) {
-	v := NewNamedVisitor(namingMap, str)
-	cst.Walk(v, root)
-	return w.Write([]byte(v.sb.String()))
-}
-
 func TestFprintTreeNamed(t *testing.T) {
 
 	f, err := os.Open("refTestFprintTreeNamed.bin")
@@ -324,7 +277,66 @@ func TestFprintTreeNamed(t *testing.T) {
 	}
 
 	sb := bytes.Buffer{}
-	FprintTreeNamed(&sb, tree, typeNames, str)
+	cst.Fprint(&sb, tree, typeNames, str, cst.PrintOptions{})
 
 	tc.ReaderContentMustBeEqual(t, f, &sb)
 }
+
+func TestFprintOptions(t *testing.T) {
+
+	p := tc.MustParser(t, `<pair> ::= <item> "," <item>` + "\n" +
+	                      `<item> ::= "a" | "\n" | ""`)
+
+	str := "a,\n"
+
+	res, err := parser.ParseToResult(p, str)
+	if err != nil {
+		t.Fatalf("Failed to parse input: %s", err.Error())
+	}
+
+	tests := []struct {
+		opts cst.PrintOptions
+		ref string
+	}{
+		{
+			opts: cst.PrintOptions{Compact: true},
+			ref: "0 pair 0:3 \"a,\\n\"\n" +
+			     "1 item 0:1 \"a\"\n" +
+			     "2 _literal 0:1 \"a\"\n" +
+			     "1 _literal 1:2 \",\"\n" +
+			     "1 item 2:3 \"\\n\"\n" +
+			     "2 _literal 2:3 \"\\n\"\n",
+		},
+		{
+			opts: cst.PrintOptions{
+				HideLiterals: true,
+				ShowSpans: true,
+				ShowLineCol: true,
+				Quote: true,
+			},
+			ref: "pair [0:3] 1:1-2:1: \"a,\\n\"\n" +
+			     "  item [0:1] 1:1-1:2: \"a\"\n" +
+			     "  item [2:3] 1:3-2:1: \"\\n\"\n",
+		},
+		{
+			opts: cst.PrintOptions{MaxDepth: 1},
+			ref: "pair: string(a,\n)\n",
+		},
+	}
+
+	for i, test := range tests {
+		sb := bytes.Buffer{}
+		n, err := cst.Fprint(&sb, res.Root, res.Symbols().Map(), str, test.opts)
+		if err != nil {
+			t.Fatalf("TEST %d: failed to print tree: %s", i, err.Error())
+		}
+		if n != sb.Len() {
+			t.Errorf("TEST %d: expected %d written bytes, got %d",
+			         i, sb.Len(), n)
+		}
+		if sb.String() != test.ref {
+			t.Errorf("TEST %d: expected:\n%s\nreturned:\n%s",
+			         i, test.ref, sb.String())
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package cst_test
+
+import (
+	"testing"
+
+	"github.com/TooManySugar/ll1parser/pkg/cst"
+	"github.com/TooManySugar/ll1parser/pkg/parser"
+	tc "github.com/TooManySugar/ll1parser/test/testcommon"
+)
+
+func TestTreeCursor(t *testing.T) {
+
+	p := tc.MustParser(t, pairGrammar)
+
+	res, err := parser.ParseToResult(p, "a,\n")
+	if err != nil {
+		t.Fatalf("Failed to parse input: %s", err.Error())
+	}
+
+	c := cst.NewTreeCursor(res.Root)
+
+	expect := func(step string, ok bool, refOk bool,
+	               refName string, refText string, refDepth int) {
+		t.Helper()
+		if ok != refOk {
+			t.Fatalf("%s: expected %v, got %v", step, refOk, ok)
+		}
+		name, text := res.Name(c.Node()), res.Text(c.Node())
+		if name != refName || text != refText || c.Depth() != refDepth {
+			t.Fatalf("%s: expected %s %q at %d, got %s %q at %d", step,
+			         refName, refText, refDepth, name, text, c.Depth())
+		}
+	}
+
+	expect("parent of root", c.GotoParent(), false, "pair", "a,\n", 0)
+	expect("sibling of root", c.GotoNextSibling(), false, "pair", "a,\n", 0)
+	expect("first child", c.GotoFirstChild(), true, "item", "a", 1)
+	expect("prev sibling", c.GotoPrevSibling(), false, "item", "a", 1)
+	expect("next sibling", c.GotoNextSibling(), true, "_literal", ",", 1)
+
+	saved := c.Copy()
+
+	expect("next sibling", c.GotoNextSibling(), true, "item", "\n", 1)
+	expect("next sibling", c.GotoNextSibling(), false, "item", "\n", 1)
+	expect("last child", c.GotoLastChild(), true, "_literal", "\n", 2)
+	expect("first child", c.GotoFirstChild(), false, "_literal", "\n", 2)
+	expect("parent", c.GotoParent(), true, "item", "\n", 1)
+	expect("prev sibling", c.GotoPrevSibling(), true, "_literal", ",", 1)
+	expect("parent", c.GotoParent(), true, "pair", "a,\n", 0)
+
+	if saved.Node().Pos() != 1 || saved.Index() != 1 || saved.Depth() != 1 {
+		t.Errorf("Copy of cursor moved with original")
+	}
+}
@@ -0,0 +1,86 @@
+package cst_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/TooManySugar/ll1parser/pkg/cst"
+	"github.com/TooManySugar/ll1parser/pkg/parser"
+	tc "github.com/TooManySugar/ll1parser/test/testcommon"
+)
+
+func TestEqualAndDiff(t *testing.T) {
+
+	p := tc.MustParser(t, pairGrammar)
+
+	parse := func(str string) *parser.ParseResult {
+		t.Helper()
+		res, err := parser.ParseToResult(p, str)
+		if err != nil {
+			t.Fatalf("Failed to parse input: %s", err.Error())
+		}
+		return res
+	}
+
+	names := parse(",").Symbols().Map()
+	literals := cst.CompareOptions{IgnoreTypes: cst.LiteralTypes(names)}
+	spans := cst.CompareOptions{IgnoreSpans: true}
+
+	tests := []struct {
+		a string
+		b string
+		opts cst.CompareOptions
+		ref []string
+	}{
+		{"a,\n", "a,\n", cst.CompareOptions{}, nil},
+		{"a,\n", "\n,a", cst.CompareOptions{}, nil},
+		{"a,", ",a", spans, []string{
+			"/pair[0]/item[0]/_literal[0]: _literal \"a\" != _nothing \"\"",
+			"/pair[0]/item[2]/_nothing[0]: _nothing \"\" != _literal \"a\"",
+		}},
+		{"a,", ",a", literals, []string{
+			"/pair[0]/item[0]: span [0:1] != [0:0]",
+			"/pair[0]/item[1]: span [2:2] != [1:2]",
+		}},
+		{"a,", ",a", cst.CompareOptions{}, []string{
+			"/pair[0]/item[0]: span [0:1] != [0:0]",
+			"/pair[0]/item[0]/_literal[0]: _literal \"a\" != _nothing \"\"",
+			"/pair[0]/_literal[1]: span [1:2] != [0:1]",
+			"/pair[0]/item[2]: span [2:2] != [1:2]",
+			"/pair[0]/item[2]/_nothing[0]: _nothing \"\" != _literal \"a\"",
+		}},
+		{"a,a", "a,\n", cst.CompareOptions{IgnoreSpans: true,
+		                                    IgnoreTypes: literals.IgnoreTypes},
+		 nil},
+	}
+
+	for _, test := range tests {
+		a, b := parse(test.a).Root, parse(test.b).Root
+		diffs := cst.Diff(a, b, names, test.a, test.b, test.opts)
+		if strings.Join(diffs, "\n") != strings.Join(test.ref, "\n") {
+			t.Errorf("Diff of %q and %q:\nexpected:\n%s\nreturned:\n%s",
+			         test.a, test.b, strings.Join(test.ref, "\n"),
+			         strings.Join(diffs, "\n"))
+		}
+
+		if cst.Equal(a, b, test.opts) != (len(test.ref) == 0) {
+			t.Errorf("Equal of %q and %q: expected %v",
+			         test.a, test.b, len(test.ref) == 0)
+		}
+	}
+
+	a := parse("a,").Root
+	b := cst.NewNode(a.Type(), a.Pos(), a.End(), a.Childs()[:2])
+	diffs := cst.Diff(a, b, names, "a,", "a,", cst.CompareOptions{})
+	ref := []string{
+		"/pair[0]: 3 childs != 2 childs",
+		"/pair[0]/item[2]: only in a: item \"\"",
+	}
+	if strings.Join(diffs, "\n") != strings.Join(ref, "\n") {
+		t.Errorf("Diff of cut tree:\nexpected:\n%s\nreturned:\n%s",
+		         strings.Join(ref, "\n"), strings.Join(diffs, "\n"))
+	}
+	if cst.Equal(a, b, cst.CompareOptions{}) {
+		t.Errorf("Equal of cut tree: expected false")
+	}
+}
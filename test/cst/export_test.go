@@ -0,0 +1,167 @@
+package cst_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/TooManySugar/ll1parser/pkg/cst"
+	"github.com/TooManySugar/ll1parser/pkg/parser"
+	"github.com/TooManySugar/ll1parser/pkg/cst/export"
+	tc "github.com/TooManySugar/ll1parser/test/testcommon"
+)
+
+const pairGrammar = `<pair> ::= <item> "," <item>` + "\n" +
+                    `<item> ::= "a" | "\n" | ""`
+
+func TestExportJSONRoundTrip(t *testing.T) {
+
+	p := tc.MustParser(t, pairGrammar)
+
+	for _, str := range []string{"a,\n", ",", "\n,a"} {
+		res, err := parser.ParseToResult(p, str)
+		if err != nil {
+			t.Fatalf("Failed to parse input: %s", err.Error())
+		}
+
+		sb := bytes.Buffer{}
+		err = export.WriteJSON(&sb, res.Root, res.Symbols().Map(), str)
+		if err != nil {
+			t.Fatalf("Failed to write JSON: %s", err.Error())
+		}
+
+		root, names, err := export.ReadJSON(&sb)
+		if err != nil {
+			t.Fatalf("Failed to read JSON: %s", err.Error())
+		}
+
+		if fmt.Sprint(root) != fmt.Sprint(res.Root) {
+			t.Errorf("Expected tree %v, got %v", res.Root, root)
+		}
+
+		for tp, name := range names {
+			if res.Symbols().Name(tp) != name {
+				t.Errorf("Expected type %d named %s, got %s",
+				         tp, res.Symbols().Name(tp), name)
+			}
+		}
+	}
+
+	_, _, err := export.ReadJSON(bytes.NewBufferString(
+		`{"names":{"0":"a"},"root":{"type":"b","start":0,"end":0}}`))
+	if err == nil {
+		t.Errorf("Expected error on unknown type name")
+	}
+}
+
+func TestExportSExprAndDOT(t *testing.T) {
+
+	p := tc.MustParser(t, pairGrammar)
+
+	str := "a,"
+	res, err := parser.ParseToResult(p, str)
+	if err != nil {
+		t.Fatalf("Failed to parse input: %s", err.Error())
+	}
+
+	sb := bytes.Buffer{}
+	export.WriteJSON(&sb, res.Root, res.Symbols().Map(), str)
+	refJSON := `{"names":{"-2":"_nothing","-1":"_literal","0":"pair","1":"item"},` +
+	           `"root":{"type":"pair","start":0,"end":2,"children":[` +
+	           `{"type":"item","start":0,"end":1,"children":[` +
+	           `{"type":"_literal","start":0,"end":1,"text":"a"}]},` +
+	           `{"type":"_literal","start":1,"end":2,"text":","},` +
+	           `{"type":"item","start":2,"end":2,"children":[` +
+	           `{"type":"_nothing","start":2,"end":2,"text":""}]}]}}` + "\n"
+	if sb.String() != refJSON {
+		t.Errorf("Expected:\n%s\nReturned:\n%s", refJSON, sb.String())
+	}
+
+	sb.Reset()
+	export.WriteSExpr(&sb, res.Root, res.Symbols().Map(), str)
+	refSExpr := `(pair (item (_literal "a")) (_literal ",") (item (_nothing "")))` +
+	            "\n"
+	if sb.String() != refSExpr {
+		t.Errorf("Expected:\n%s\nReturned:\n%s", refSExpr, sb.String())
+	}
+
+	sb.Reset()
+	export.WriteDOT(&sb, res.Root.Childs()[1], res.Symbols().Map(), str)
+	refDOT := "digraph cst {\n" +
+	          "\tnode [shape=box];\n" +
+	          "\tn0 [label=\"_literal [1:2]\\n\\\",\\\"\"];\n" +
+	          "}\n"
+	if sb.String() != refDOT {
+		t.Errorf("Expected:\n%s\nReturned:\n%s", refDOT, sb.String())
+	}
+}
+
+func TestExportBinaryRoundTrip(t *testing.T) {
+
+	p := tc.MustParser(t, pairGrammar)
+
+	for _, str := range []string{"a,\n", ",", "\n,a"} {
+		res, err := parser.ParseToResult(p, str)
+		if err != nil {
+			t.Fatalf("Failed to parse input: %s", err.Error())
+		}
+
+		sb := bytes.Buffer{}
+		err = export.WriteBinary(&sb, res.Root, res.Symbols().Map(), str)
+		if err != nil {
+			t.Fatalf("Failed to write binary: %s", err.Error())
+		}
+		data := sb.Bytes()
+
+		root, names, err := export.ReadBinary(bytes.NewReader(data), str)
+		if err != nil {
+			t.Fatalf("Failed to read binary: %s", err.Error())
+		}
+
+		diffs := cst.Diff(res.Root, root, names, str, str, cst.CompareOptions{})
+		if len(diffs) > 0 {
+			t.Errorf("Trees of %q differ:\n%s", str, strings.Join(diffs, "\n"))
+		}
+
+		for tp, name := range res.Symbols().Map() {
+			if names[tp] != name {
+				t.Errorf("Expected type %d named %s, got %s",
+				         tp, name, names[tp])
+			}
+		}
+
+		_, _, err = export.ReadBinary(bytes.NewReader(data), str + " ")
+		if err != export.ErrSourceMismatch {
+			t.Errorf("Expected source mismatch error, got %v", err)
+		}
+
+		for i := 0; i < len(data); i++ {
+			_, _, err = export.ReadBinary(bytes.NewReader(data[:i]), str)
+			if err == nil {
+				t.Errorf("Expected error on data cut at %d", i)
+			}
+		}
+	}
+
+	// root start far out of source must not overflow span check
+	str := "a,a"
+	sum := sha256.Sum256([]byte(str))
+	data := append([]byte("LL1CST\x01"), sum[:]...)
+	data = binary.AppendUvarint(data, 1)
+	data = binary.AppendVarint(data, 0)
+	data = binary.AppendUvarint(data, 4)
+	data = append(data, "pair"...)
+	data = binary.AppendVarint(data, 0)
+	data = binary.AppendVarint(data, math.MaxInt64)
+	data = binary.AppendUvarint(data, 1)
+	data = binary.AppendUvarint(data, 0)
+
+	_, _, err := export.ReadBinary(bytes.NewReader(data), str)
+	if err == nil {
+		t.Errorf("Expected error on node start out of source")
+	}
+}
@@ -0,0 +1,94 @@
+package cst_test
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"github.com/TooManySugar/ll1parser/pkg/cst"
+	"github.com/TooManySugar/ll1parser/pkg/parser"
+	tc "github.com/TooManySugar/ll1parser/test/testcommon"
+)
+
+func TestIterator(t *testing.T) {
+
+	p := tc.MustParser(t, pairGrammar)
+
+	res, err := parser.ParseToResult(p, "a,\n")
+	if err != nil {
+		t.Fatalf("Failed to parse input: %s", err.Error())
+	}
+
+	var sb strings.Builder
+	it := cst.NewIterator(res.Root)
+	if it.Node() != nil {
+		t.Errorf("Expected no node before Next")
+	}
+	for it.Next() {
+		fmt.Fprintf(&sb, "%d %s %q\n", it.Depth(),
+		            res.Name(it.Node()), res.Text(it.Node()))
+		if it.Node().Pos() == 0 && res.Name(it.Node()) == "item" {
+			it.SkipChildren()
+		}
+	}
+	if it.Node() != nil || it.Next() {
+		t.Errorf("Expected no nodes after end")
+	}
+
+	ref := "0 pair \"a,\\n\"\n" +
+	       "1 item \"a\"\n" +
+	       "1 _literal \",\"\n" +
+	       "1 item \"\\n\"\n" +
+	       "2 _literal \"\\n\"\n"
+	if sb.String() != ref {
+		t.Errorf("Expected:\n%s\nReturned:\n%s", ref, sb.String())
+	}
+}
+
+type countingVisitor struct {
+	count *int
+}
+
+func (v countingVisitor) Visit(node cst.Node) cst.Visitor {
+	*v.count++
+	return v
+}
+
+func TestWalkDeepTree(t *testing.T) {
+
+	// spine like right recursive rules produce, deep enough to overflow
+	// limited stack if walked recursively
+	const depth = 100000
+	root := cst.NewNode(0, depth, depth, nil)
+	for i := depth - 1; i >= 0; i-- {
+		leaf := cst.NewNode(1, i, i + 1, nil)
+		root = cst.NewNode(0, i, depth, []cst.Node{leaf, root})
+	}
+
+	defer debug.SetMaxStack(debug.SetMaxStack(256 * 1024))
+
+	count := 0
+	cst.Walk(countingVisitor{&count}, root)
+	if count != 2 * depth + 1 {
+		t.Errorf("Walk: expected %d nodes, got %d", 2 * depth + 1, count)
+	}
+
+	count = 0
+	cst.Traverse(root, nil, func(cst.Node) error {
+		count++
+		return nil
+	})
+	if count != 2 * depth + 1 {
+		t.Errorf("Traverse: expected %d exits, got %d", 2 * depth + 1, count)
+	}
+}
+
+func TestWalkNilRoot(t *testing.T) {
+
+	count := 0
+	cst.Walk(countingVisitor{&count}, nil)
+	if count != 1 {
+		t.Errorf("Expected nil root visited once, got %d visits", count)
+	}
+}
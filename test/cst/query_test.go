@@ -0,0 +1,129 @@
+package cst_test
+
+import (
+	"testing"
+
+	"github.com/TooManySugar/ll1parser/pkg/cst"
+	"github.com/TooManySugar/ll1parser/pkg/parser"
+	"github.com/TooManySugar/ll1parser/pkg/cst/query"
+	tc "github.com/TooManySugar/ll1parser/test/testcommon"
+)
+
+const objectGrammar = `<object> ::= "{" <members> "}"` + "\n" +
+                      `<members> ::= "" | <key-value> <members-tail>` + "\n" +
+                      `<members-tail> ::= "" | "," <key-value> <members-tail>` + "\n" +
+                      `<key-value> ::= <key> ":" <value>` + "\n" +
+                      `<key> ::= "a" | "b"` + "\n" +
+                      `<value> ::= <object> | "1" | "2"`
+
+func TestQueryMatch(t *testing.T) {
+
+	p := tc.MustParser(t, objectGrammar)
+
+	res, err := parser.ParseToResult(p, "{a:1,b:{a:2}}")
+	if err != nil {
+		t.Fatalf("Failed to parse input: %s", err.Error())
+	}
+
+	names := res.Symbols().Map()
+
+	tests := []struct {
+		query string
+		ref []string
+	}{
+		{"key-value > key", []string{"a", "b", "a"}},
+		{"/object > members > key-value > value", []string{"1"}},
+		{"/object//key-value > value", []string{"1", "{a:2}", "2"}},
+		{"object object key", []string{"a"}},
+		{"//value[0]", []string{"1", "{a:2}", "2"}},
+		{"key-value > _literal[0]", []string{":", ":", ":"}},
+		{"members-tail > *[1]", []string{"b:{a:2}"}},
+		{"/members", nil},
+		{"no-such-rule", nil},
+	}
+
+	for _, test := range tests {
+		q, err := query.Compile(test.query)
+		if err != nil {
+			t.Errorf("Failed to compile `%s`: %s", test.query, err.Error())
+			continue
+		}
+
+		matched := q.Match(res.Root, names)
+
+		var texts []string
+		for _, node := range matched {
+			texts = append(texts, res.Text(node))
+		}
+
+		if len(texts) != len(test.ref) {
+			t.Errorf("`%s`: expected %q, got %q", test.query, test.ref, texts)
+			continue
+		}
+		for i := range texts {
+			if texts[i] != test.ref[i] {
+				t.Errorf("`%s`: expected %q, got %q", test.query, test.ref, texts)
+				break
+			}
+		}
+	}
+}
+
+func TestQueryCaptures(t *testing.T) {
+
+	p := tc.MustParser(t, objectGrammar)
+
+	res, err := parser.ParseToResult(p, "{a:1,b:{a:2}}")
+	if err != nil {
+		t.Fatalf("Failed to parse input: %s", err.Error())
+	}
+
+	q := query.MustCompile("key-value@kv > key@k")
+	matches := q.MatchCaptures(res.Root, res.Symbols().Map())
+
+	ref := [][2]string{{"a:1", "a"}, {"b:{a:2}", "b"}, {"a:2", "a"}}
+	if len(matches) != len(ref) {
+		t.Fatalf("Expected %d matches, got %d", len(ref), len(matches))
+	}
+
+	for i, m := range matches {
+		kv := res.Text(m.Captures["kv"])
+		k := res.Text(m.Captures["k"])
+		if kv != ref[i][0] || k != ref[i][1] ||
+		   m.Node.Pos() != m.Captures["k"].Pos() {
+			t.Errorf("Match %d: expected %v, got [%s %s]", i, ref[i], kv, k)
+		}
+	}
+}
+
+func TestQueryDeepDescendants(t *testing.T) {
+
+	// b root with chain of nested a nodes
+	const depth = 100
+	names := map[int]string{0: "a", 1: "b", 2: "c"}
+	root := cst.NewNode(0, 0, 0, nil)
+	for i := 1; i < depth; i++ {
+		root = cst.NewNode(0, 0, 0, []cst.Node{root})
+	}
+	root = cst.NewNode(1, 0, 0, []cst.Node{root})
+
+	// every a below two a's
+	res := query.MustCompile("b a a").Match(root, names)
+	if len(res) != depth - 1 {
+		t.Errorf("Expected %d matches, got %d", depth - 1, len(res))
+	}
+
+	// failing descendant steps are not retried for every ancestor
+	res = query.MustCompile("c a a a a a a a a a a").Match(root, names)
+	if len(res) != 0 {
+		t.Errorf("Expected no matches, got %d", len(res))
+	}
+}
+
+func TestQueryCompileErrors(t *testing.T) {
+	for _, src := range []string{"", "a >", "> a", "a[x]", "a[1", "a@", "a!"} {
+		if _, err := query.Compile(src); err == nil {
+			t.Errorf("Expected `%s` to fail", src)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package cst_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/TooManySugar/ll1parser/pkg/cst"
+	"github.com/TooManySugar/ll1parser/pkg/parser"
+	"github.com/TooManySugar/ll1parser/pkg/cst/rewrite"
+	"github.com/TooManySugar/ll1parser/pkg/cst/transform"
+	tc "github.com/TooManySugar/ll1parser/test/testcommon"
+)
+
+func TestRewrite(t *testing.T) {
+
+	p := tc.MustParser(t, listGrammar)
+
+	str := "[1,2,3]"
+	res, err := parser.ParseToResult(p, str)
+	if err != nil {
+		t.Fatalf("Failed to parse input: %s", err.Error())
+	}
+
+	names := res.Symbols().Map()
+
+	untouched := rewrite.New(res.Root, str)
+	if untouched.Text() != str {
+		t.Errorf("Expected untouched text %q, got %q", str, untouched.Text())
+	}
+	if !cst.Equal(untouched.ToNode(), res.Root, cst.CompareOptions{}) {
+		t.Errorf("Untouched tree differs from parsed")
+	}
+
+	// with commas and brackets out of tree they are kept as bytes between
+	// nodes and go away only together with following node
+	tree, err := transform.Apply(res.Root, names, str, transform.Config{
+		Flatten: []string{"items-tail"},
+		DropNothing: true,
+		DropLiterals: []string{",", "[", "]"},
+		Collapse: []string{"value"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to transform: %s", err.Error())
+	}
+
+	root := rewrite.New(tree, str)
+	items := root.Childs()[0]
+	tail := items.Childs()[1]
+
+	expectText := func(step string, ref string) {
+		t.Helper()
+		if root.Text() != ref {
+			t.Fatalf("%s: expected %q, got %q", step, ref, root.Text())
+		}
+	}
+
+	removed, err := tail.RemoveChild(0)
+	if err != nil {
+		t.Fatalf("Failed to remove child: %s", err.Error())
+	}
+	expectText("remove", "[1,3]")
+
+	items.Childs()[0].Replace("2")
+	expectText("replace", "[2,3]")
+
+	digit, _ := res.TypeOf("digit")
+	literal, _ := res.TypeOf("_literal")
+	err = tail.InsertChild(2, rewrite.NewLeaf(digit, "4"))
+	if err == nil {
+		t.Errorf("Expected error on out of range insert")
+	}
+	tail.InsertChild(1, rewrite.NewLeaf(literal, ","))
+	tail.InsertChild(2, removed)
+	expectText("insert", "[2,3,2]")
+
+	err = tail.InsertChild(0, items.Childs()[0])
+	if err == nil {
+		t.Errorf("Expected error on insert of node with parent")
+	}
+
+	sb := bytes.Buffer{}
+	cst.Fprint(&sb, root.ToNode(), names, root.Text(),
+	           cst.PrintOptions{Compact: true})
+	ref := "0 list 0:7 \"[2,3,2]\"\n" +
+	       "1 items 1:6 \"2,3,2\"\n" +
+	       "2 digit 1:2 \"2\"\n" +
+	       "2 items-tail 2:6 \",3,2\"\n" +
+	       "3 digit 3:4 \"3\"\n" +
+	       "4 _literal 3:4 \"3\"\n" +
+	       "3 _literal 4:5 \",\"\n" +
+	       "3 digit 5:6 \"2\"\n" +
+	       "4 _literal 5:6 \"2\"\n"
+	if sb.String() != ref {
+		t.Errorf("Expected tree:\n%s\ngot:\n%s", ref, sb.String())
+	}
+}
@@ -0,0 +1,92 @@
+package cst_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/TooManySugar/ll1parser/pkg/cst"
+	"github.com/TooManySugar/ll1parser/pkg/parser"
+	"github.com/TooManySugar/ll1parser/pkg/cst/transform"
+	tc "github.com/TooManySugar/ll1parser/test/testcommon"
+)
+
+const listGrammar = `<list> ::= "[" <items> "]"` + "\n" +
+                    `<items> ::= "" | <value> <items-tail>` + "\n" +
+                    `<items-tail> ::= "" | "," <value> <items-tail>` + "\n" +
+                    `<value> ::= <digit>` + "\n" +
+                    `<digit> ::= "1" | "2" | "3"`
+
+func TestTransformApply(t *testing.T) {
+
+	p := tc.MustParser(t, listGrammar)
+
+	str := "[1,2,3]"
+	res, err := parser.ParseToResult(p, str)
+	if err != nil {
+		t.Fatalf("Failed to parse input: %s", err.Error())
+	}
+
+	names := res.Symbols().Map()
+
+	tests := []struct {
+		cfg transform.Config
+		ref string
+	}{
+		{
+			cfg: transform.Config{
+				Flatten: []string{"items-tail"},
+				DropNothing: true,
+				DropLiterals: []string{",", "[", "]"},
+				Collapse: []string{"value"},
+			},
+			ref: "0 list 0:7 \"[1,2,3]\"\n" +
+			     "1 items 1:6 \"1,2,3\"\n" +
+			     "2 digit 1:2 \"1\"\n" +
+			     "3 _literal 1:2 \"1\"\n" +
+			     "2 items-tail 2:6 \",2,3\"\n" +
+			     "3 digit 3:4 \"2\"\n" +
+			     "4 _literal 3:4 \"2\"\n" +
+			     "3 digit 5:6 \"3\"\n" +
+			     "4 _literal 5:6 \"3\"\n",
+		},
+		{
+			cfg: transform.Config{
+				Collapse: []string{"value", "digit"},
+				DropLiterals: []string{"2"},
+			},
+			ref: "0 list 0:7 \"[1,2,3]\"\n" +
+			     "1 _literal 0:1 \"[\"\n" +
+			     "1 items 1:6 \"1,2,3\"\n" +
+			     "2 _literal 1:2 \"1\"\n" +
+			     "2 items-tail 2:6 \",2,3\"\n" +
+			     "3 _literal 2:3 \",\"\n" +
+			     "3 digit 3:4 \"2\"\n" +
+			     "3 items-tail 4:6 \",3\"\n" +
+			     "4 _literal 4:5 \",\"\n" +
+			     "4 _literal 5:6 \"3\"\n" +
+			     "4 items-tail 6:6 \"\"\n" +
+			     "5 _nothing 6:6 \"\"\n" +
+			     "1 _literal 6:7 \"]\"\n",
+		},
+	}
+
+	for i, test := range tests {
+		root, err := transform.Apply(res.Root, names, str, test.cfg)
+		if err != nil {
+			t.Fatalf("TEST %d: failed to transform: %s", i, err.Error())
+		}
+
+		sb := bytes.Buffer{}
+		cst.Fprint(&sb, root, names, str, cst.PrintOptions{Compact: true})
+		if sb.String() != test.ref {
+			t.Errorf("TEST %d: expected:\n%s\nreturned:\n%s",
+			         i, test.ref, sb.String())
+		}
+	}
+
+	_, err = transform.Apply(res.Root, names, str,
+	                         transform.Config{Flatten: []string{"no-such"}})
+	if err == nil {
+		t.Errorf("Expected error on unknown rule name")
+	}
+}
@@ -0,0 +1,101 @@
+package cst_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/TooManySugar/ll1parser/pkg/cst"
+	"github.com/TooManySugar/ll1parser/pkg/parser"
+	tc "github.com/TooManySugar/ll1parser/test/testcommon"
+)
+
+func TestTraverse(t *testing.T) {
+
+	p := tc.MustParser(t, pairGrammar)
+
+	res, err := parser.ParseToResult(p, "a,\n")
+	if err != nil {
+		t.Fatalf("Failed to parse input: %s", err.Error())
+	}
+
+	var sb strings.Builder
+	record := func(prefix string, err error) func(cst.Node) error {
+		return func(node cst.Node) error {
+			sb.WriteString(prefix + res.Name(node) + " ")
+			return err
+		}
+	}
+
+	check := func(what string, ref string) {
+		t.Helper()
+		if sb.String() != ref {
+			t.Errorf("%s: expected `%s`, got `%s`", what, ref, sb.String())
+		}
+		sb.Reset()
+	}
+
+	err = cst.Traverse(res.Root, record("+", nil), record("-", nil))
+	if err != nil {
+		t.Fatalf("Traverse failed: %s", err.Error())
+	}
+	check("enter and exit", "+pair +item +_literal -_literal -item " +
+	                        "+_literal -_literal " +
+	                        "+item +_literal -_literal -item -pair ")
+
+	skipItems := func(node cst.Node) error {
+		record("+", nil)(node)
+		if res.Name(node) == "item" {
+			return cst.SkipChildren
+		}
+		return nil
+	}
+	cst.Traverse(res.Root, skipItems, record("-", nil))
+	check("skip childs", "+pair +item -item +_literal -_literal " +
+	                     "+item -item -pair ")
+
+	cst.Traverse(res.Root, nil, record("-", nil))
+	check("exit only", "-_literal -item -_literal -_literal -item -pair ")
+
+	err = cst.Traverse(res.Root, record("+", nil), record("-", cst.SkipAll))
+	if err != nil {
+		t.Errorf("SkipAll returned as error: %s", err.Error())
+	}
+	check("skip all", "+pair +item +_literal -_literal ")
+
+	refErr := errors.New("ref")
+	err = cst.Traverse(res.Root, record("+", refErr), nil)
+	if err != refErr {
+		t.Errorf("expected error `%v`, got `%v`", refErr, err)
+	}
+	check("error", "+pair ")
+}
+
+func TestInspect(t *testing.T) {
+
+	p := tc.MustParser(t, pairGrammar)
+
+	res, err := parser.ParseToResult(p, "a,\n")
+	if err != nil {
+		t.Fatalf("Failed to parse input: %s", err.Error())
+	}
+
+	var sb strings.Builder
+	cst.Inspect(res.Root, func(node cst.Node) bool {
+		if node == nil {
+			sb.WriteString(") ")
+			return true
+		}
+		sb.WriteString(res.Name(node) + " ")
+		if res.Name(node) == "item" {
+			return false
+		}
+		sb.WriteString("( ")
+		return true
+	})
+
+	ref := "pair ( item _literal ( ) item ) "
+	if sb.String() != ref {
+		t.Errorf("expected `%s`, got `%s`", ref, sb.String())
+	}
+}
@@ -28,6 +28,24 @@ var tests = []testDir{
 		name: "cst",
 		tests: []string {
 			"TestFprintTreeNamed",
+			"TestFprintOptions",
+			"TestExportJSONRoundTrip",
+			"TestExportSExprAndDOT",
+			"TestExportBinaryRoundTrip",
+			"TestTransformApply",
+			"TestQueryMatch",
+			"TestQueryCaptures",
+			"TestQueryDeepDescendants",
+			"TestQueryCompileErrors",
+			"TestTreeCursor",
+			"TestNodeAt",
+			"TestTraverse",
+			"TestInspect",
+			"TestIterator",
+			"TestWalkDeepTree",
+			"TestWalkNilRoot",
+			"TestEqualAndDiff",
+			"TestRewrite",
 		},
 	},
 	{
@@ -39,6 +57,47 @@ var tests = []testDir{
 			"TestResolvedLRecursiveParserParse",
 			"TestBNFParserCanParse1",
 			"TestParserParseNamingMap",
+			"TestParserParseResult",
+			"TestDocumentScanner",
+			"TestSplitFunc",
+			"TestReparseEqualToFullParse",
+			"TestReparseChainedEdits",
+			"TestReparseReusesOldTree",
+			"TestParseValueEvaluates",
+			"TestParseValueErrors",
+			"TestSyntaxErrorPosition",
+			"TestErrorPrinterFprint",
+			"TestCaseInsensitiveGrammar",
+			"TestCaseInsensitiveParserParse",
+			"TestValidateParsedTrees",
+			"TestValidateViolations",
+			"TestValidateIndirectlyEmptyRule",
+			"TestEBNFGrammarDesugar",
+			"TestEBNFParserParse",
+			"TestBNFCommentsAndMultiLineRules",
+			"TestBNFDialectErrors",
+			"TestBNFCommentsToEndOfFile",
+			"TestFromCSTRuleTypeBindings",
+			"TestABNFGrammar",
+			"TestABNFParserParse",
+			"TestABNFErrors",
+			"TestW3CEBNFGrammar",
+			"TestW3CEBNFParserParse",
+			"TestW3CEBNFErrors",
+			"TestW3CEBNFCharacters",
+			"TestLoaderLoad",
+			"TestLoaderErrors",
+			"TestFormatGrammar",
+			"TestFormatGrammarRepetitions",
+			"TestFormatSource",
+			"TestFormatSourceImports",
+		},
+	},
+	{
+		name: "token",
+		tests: []string {
+			"TestFileSetPosition",
+			"TestFileSetParseErrors",
 		},
 	},
 }
@@ -0,0 +1,115 @@
+package bnf_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/TooManySugar/ll1parser/pkg/bnf/abnf"
+	"github.com/TooManySugar/ll1parser/pkg/bnf/tablegen"
+	"github.com/TooManySugar/ll1parser/pkg/parser"
+)
+
+const abnfGrammar =
+	"; key value list\r\n" +
+	"list   = pair *( \",\" pair )\n" +
+	"         [ \";\" ]   ; optional terminator\n" +
+	"\n" +
+	"pair   = key \"=\" value\n" +
+	"key    = 1*( %x61-63 / \"-\" )\n" +
+	"Value  = number / flag / text\n" +
+	"value  =/ \"@\" %s\"Ref\"\n" +
+	"number = %x31-33 *2digit\n" +
+	"flag   = \"on\"\n" +
+	"text   = %x27 *%x61.62 %d39"
+
+func TestABNFGrammar(t *testing.T) {
+
+	g, err := abnf.Parse(abnfGrammar)
+	if err != nil {
+		t.Fatalf("Failed to parse ABNF: %s", err.Error())
+	}
+
+	ref := `<list> ::= <pair> ("," <pair>)* (";")?` + "\n" +
+	       `<pair> ::= <key> "=" <Value>` + "\n" +
+	       `<key> ::= ("a" | "b" | "c" | "-")+` + "\n" +
+	       `<Value> ::= <number> | <flag> | <text> | "@" "Ref"` + "\n" +
+	       `<number> ::= ("1" | "2" | "3") <DIGIT>{0,2}` + "\n" +
+	       `<flag> ::= "on"i` + "\n" +
+	       `<text> ::= "'" "ab"* "'"` + "\n" +
+	       `<DIGIT> ::= "0" | "1" | "2" | "3" | "4" | "5" | "6" | "7" | ` +
+	                   `"8" | "9"`
+	if g.String() != ref {
+		t.Errorf("Expected grammar string:\n%s\nReturned:\n%s",
+		         ref, g.String())
+	}
+}
+
+func TestABNFParserParse(t *testing.T) {
+
+	g, err := abnf.Parse(abnfGrammar)
+	if err != nil {
+		t.Fatalf("Failed to parse ABNF: %s", err.Error())
+	}
+
+	table, names, err := tablegen.FromGrammar(*g)
+	if err != nil {
+		t.Fatalf("Failed to build parser table: %s", err.Error())
+	}
+	p := parser.NewLL1Parser(*table, *names)
+
+	tests := []struct {
+		src string
+		ok bool
+	}{
+		{"a=12,b-c=ON,ca='abab',b=@Ref;", true},
+		{"a=3", true},
+		{"a=1234", false},
+		{"a=@ref", false},
+		{"a='aba'", false},
+	}
+
+	for _, test := range tests {
+		_, _, err := p.Parse(test.src)
+		if (err == nil) != test.ok {
+			t.Errorf("Parse %q: expected success %t, got error %v",
+			         test.src, test.ok, err)
+		}
+	}
+}
+
+func TestABNFErrors(t *testing.T) {
+
+	tests := []struct {
+		src string
+		err string
+	}{
+		{"a = <prose>", "1:5: prose value <prose> is not supported"},
+		{"a = b", "1:5: rule b is not defined"},
+		{"a = \"x\"\nA = \"y\"", "2:1: rule A is already defined"},
+		{"a =/ \"x\"", "1:1: =/ adds alternatives to undefined rule a"},
+		{"a = \"x\" /\n  / \"y\"", "2:3: empty alternative"},
+		{"a = \"x\" /", "1:10: empty alternative"},
+		{"a = ( \"x\" ]", "1:11: ] closes ("},
+		{"a = %x00", "1:7: zero byte marks end of input and can't be matched"},
+		{"a = %x100", "1:7: value 100 is out of byte range"},
+		{"a = %b12", "1:7: invalid number 12 in base 2"},
+		{"a = %x5A-41", "1:6: range x5A-41 is empty"},
+		{"a = 3*2\"x\"", "1:5: repeat maximum is less than minimum"},
+		{"  \"x\"\na = \"y\"", "1:1: indented line before first rule"},
+		{"; comment only\n", "no rules defined"},
+	}
+
+	for _, test := range tests {
+		_, err := abnf.Parse(test.src)
+		if err == nil || err.Error() != test.err {
+			t.Errorf("Parse %q: expected error %q, got %v",
+			         test.src, test.err, err)
+		}
+	}
+
+	_, err := abnf.Parse("a = \"x\" \"y\"z")
+	var syntaxErr *parser.SyntaxError
+	if !errors.As(err, &syntaxErr) || syntaxErr.Offset != 11 {
+		t.Errorf("Expected syntax error at offset 11, got %v", err)
+	}
+}
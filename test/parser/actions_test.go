@@ -0,0 +1,97 @@
+package bnf_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/TooManySugar/ll1parser/pkg/parser"
+	tc "github.com/TooManySugar/ll1parser/test/testcommon"
+)
+
+const sumGrammar =
+	`<sum> ::= <num> <sum-tail>` + "\n" +
+	`<sum-tail> ::= "" | "+" <num> <sum-tail>` + "\n" +
+	`<num> ::= <digit> <num-tail>` + "\n" +
+	`<num-tail> ::= "" | <digit> <num-tail>` + "\n" +
+	`<digit> ::= "0" | "1" | "2" | "3" | "4" | "5" | "6" | "7" | "8" | "9"`
+
+func TestParseValueEvaluates(t *testing.T) {
+
+	p := tc.MustParser(t, sumGrammar)
+
+	src := "12+3+40"
+
+	var spans [][2]int
+	actions := map[string]parser.ReduceFunc{
+		"sum": func(childs []any, start int, end int) (any, error) {
+			return childs[0].(int) + childs[1].(int), nil
+		},
+		"sum-tail": func(childs []any, start int, end int) (any, error) {
+			if len(childs) == 0 {
+				return 0, nil
+			}
+			if childs[0] != "+" {
+				return nil, errors.New("expected + literal value")
+			}
+			return childs[1].(int) + childs[2].(int), nil
+		},
+		"num": func(childs []any, start int, end int) (any, error) {
+			spans = append(spans, [2]int{start, end})
+			return strconv.Atoi(src[start:end])
+		},
+	}
+
+	res, err := parser.ParseValue(p, src, actions)
+	if err != nil {
+		t.Fatalf("Failed to parse input: %s", err.Error())
+	}
+
+	if res != 55 {
+		t.Errorf("Expected 55, got %v", res)
+	}
+
+	refSpans := [][2]int{{0, 2}, {3, 4}, {5, 7}}
+	if len(spans) != len(refSpans) {
+		t.Fatalf("Expected <num> spans %v, got %v", refSpans, spans)
+	}
+	for i := range spans {
+		if spans[i] != refSpans[i] {
+			t.Errorf("Expected <num> spans %v, got %v", refSpans, spans)
+		}
+	}
+}
+
+func TestParseValueErrors(t *testing.T) {
+
+	p := tc.MustParser(t, sumGrammar)
+
+	_, err := parser.ParseValue(p, "1", map[string]parser.ReduceFunc{
+		"no-such-rule": nil,
+	})
+	if err == nil {
+		t.Errorf("Expected error on action for unknown rule")
+	}
+
+	_, err = parser.ParseValue(p, "1", map[string]parser.ReduceFunc{
+		"num": nil,
+	})
+	if err == nil {
+		t.Errorf("Expected error on nil action")
+	}
+
+	actionErr := errors.New("action error")
+	_, err = parser.ParseValue(p, "1+2", map[string]parser.ReduceFunc{
+		"num": func([]any, int, int) (any, error) {
+			return nil, actionErr
+		},
+	})
+	if err != actionErr {
+		t.Errorf("Expected action's error, got %v", err)
+	}
+
+	_, err = parser.ParseValue(p, "1+", nil)
+	if err == nil {
+		t.Errorf("Expected parsing error")
+	}
+}
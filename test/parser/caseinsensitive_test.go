@@ -0,0 +1,53 @@
+package bnf_test
+
+import (
+	"testing"
+
+	"github.com/TooManySugar/ll1parser/pkg/bnf"
+	"github.com/TooManySugar/ll1parser/pkg/parser"
+	tc "github.com/TooManySugar/ll1parser/test/testcommon"
+)
+
+const caseInsensitiveGrammar =
+	`<stmt> ::= "select"i " " <cols> | "drop" " " <cols>` + "\n" +
+	`<cols> ::= "x" | "Y"i`
+
+func TestCaseInsensitiveGrammar(t *testing.T) {
+
+	g := tc.MustGrammar(t, caseInsensitiveGrammar)
+
+	sel := g.Rules[0].Tail.Sequences[0].Symbols[0].(bnf.SymbolTerminal)
+	drop := g.Rules[0].Tail.Sequences[1].Symbols[0].(bnf.SymbolTerminal)
+	if !sel.CaseInsensitive || drop.CaseInsensitive {
+		t.Errorf("Expected only \"select\" to be case insensitive")
+	}
+
+	if g.String() != caseInsensitiveGrammar {
+		t.Errorf("Expected grammar string:\n%s\nReturned:\n%s",
+		         caseInsensitiveGrammar, g.String())
+	}
+}
+
+func TestCaseInsensitiveParserParse(t *testing.T) {
+
+	p := tc.MustParser(t, caseInsensitiveGrammar)
+
+	for _, src := range []string{"select x", "SELECT x", "SeLeCt y", "drop Y"} {
+		res, err := parser.ParseToResult(p, src)
+		if err != nil {
+			t.Errorf("Failed to parse %q: %s", src, err.Error())
+			continue
+		}
+		if res.Text(res.Root.Childs()[0]) != src[:len(src) - 2] {
+			t.Errorf("Expected keyword text %q, got %q",
+			         src[:len(src) - 2], res.Text(res.Root.Childs()[0]))
+		}
+	}
+
+	for _, src := range []string{"DROP x", "select X", "selekt x"} {
+		_, _, err := p.Parse(src)
+		if err == nil {
+			t.Errorf("Expected %q to fail", src)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package bnf_test
+
+import (
+	"testing"
+
+	tc "github.com/TooManySugar/ll1parser/test/testcommon"
+)
+
+const dialectGrammar =
+	"; list of words\n" +
+	"<list> ::= <word>\n" +
+	"\t( \",\" <word> )*   # more words\n" +
+	"\n" +
+	"  <word> ::= \"a\"\n" +
+	"    | \"b\" ; second\r\n" +
+	"\t| <list>\t\"!\"\n" +
+	"# trailing comment\n"
+
+func TestBNFCommentsAndMultiLineRules(t *testing.T) {
+
+	g := tc.MustGrammar(t, dialectGrammar)
+
+	ref := `<list> ::= <word> ("," <word>)*` + "\n" +
+	       `<word> ::= "a" | "b" | <list> "!"`
+	if g.String() != ref {
+		t.Errorf("Expected grammar string:\n%s\nReturned:\n%s",
+		         ref, g.String())
+	}
+
+	// literals keep comment characters and tabs are only whitespace
+	g = tc.MustGrammar(t, "<a> ::= \";\" \"#\"\t\"\\t\"")
+
+	ref = `<a> ::= ";" "#" "\t"`
+	if g.String() != ref {
+		t.Errorf("Expected grammar string:\n%s\nReturned:\n%s",
+		         ref, g.String())
+	}
+}
+
+func TestBNFDialectErrors(t *testing.T) {
+
+	tests := []struct {
+		name string
+		src string
+	}{
+		{"empty rule", "<a> ::=\n<b> ::= \"b\""},
+		{"empty alternative", "<a> ::= \"a\" |\n| \"b\""},
+		{"empty group", "<a> ::= ()"},
+		{"head in group", "<a> ::= (\"a\" <b> ::= \"b\")"},
+		{"no rule head", "; comment only\n"},
+		{"non-ASCII literal", "<a> ::= \"é\""},
+		{"control byte in literal", "<a> ::= \"\x01\""},
+		{"continuation not indented", "<a> ::= \"a\"\n\"b\""},
+		{"alternative not indented", "<a> ::= \"a\"\r\n| \"b\""},
+	}
+
+	for _, test := range tests {
+		_, err := tc.Grammar(t, test.src)
+		if err == nil {
+			t.Errorf("%s: expected error on %q", test.name, test.src)
+		}
+	}
+}
+
+func TestBNFCommentsToEndOfFile(t *testing.T) {
+
+	tests := []struct {
+		src string
+		ref string
+	}{
+		{"<a> ::= \"a\" ; comment", `<a> ::= "a"`},
+		{"<a> ::= \"a\"\n# trailing comment", `<a> ::= "a"`},
+		{"; grammar of café\n<a> ::= \"a\" ; naïve\r\n<b> ::= \"b\"",
+		 `<a> ::= "a"` + "\n" + `<b> ::= "b"`},
+		{"<a> ::= \"a\" ; \x01 control \x7f bytes\n", `<a> ::= "a"`},
+		// other bytes are written in literals with \xHH
+		{"<a> ::= \"\\xC3\\xA9\" '\\x01'", `<a> ::= "\xC3\xA9" "\x01"`},
+	}
+
+	for _, test := range tests {
+		g, err := tc.Grammar(t, test.src)
+		if err != nil {
+			t.Errorf("Failed to parse %q: %s", test.src, err.Error())
+			continue
+		}
+		if g.String() != test.ref {
+			t.Errorf("Expected grammar string of %q:\n%s\nReturned:\n%s",
+			         test.src, test.ref, g.String())
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package bnf_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/TooManySugar/ll1parser/pkg/bnf"
+	"github.com/TooManySugar/ll1parser/pkg/bnf/validate"
+	"github.com/TooManySugar/ll1parser/pkg/cst"
+	"github.com/TooManySugar/ll1parser/pkg/parser"
+	"github.com/TooManySugar/ll1parser/pkg/cst/transform"
+	tc "github.com/TooManySugar/ll1parser/test/testcommon"
+)
+
+const ebnfGrammar =
+	`<list> ::= "[" (<item> ("," <item>)*)? "]"` + "\n" +
+	`<item> ::= ("a" | "b")+ "!"? | <list>`
+
+func TestEBNFGrammarDesugar(t *testing.T) {
+
+	g := tc.MustGrammar(t, ebnfGrammar)
+
+	if g.String() != ebnfGrammar {
+		t.Errorf("Expected grammar string:\n%s\nReturned:\n%s",
+		         ebnfGrammar, g.String())
+	}
+
+	dg, err := bnf.Desugar(g)
+	if err != nil {
+		t.Fatalf("Failed to desugar grammar: %s", err.Error())
+	}
+
+	ref := `<list> ::= "[" <list.2> "]"` + "\n" +
+	       `<item> ::= <item.1> <item.2> <item.3> | <list>` + "\n" +
+	       `<list.1> ::= "" | "," <item> <list.1>` + "\n" +
+	       `<list.2> ::= "" | <item> <list.1>` + "\n" +
+	       `<item.1> ::= "a" | "b"` + "\n" +
+	       `<item.2> ::= "" | "a" <item.2> | "b" <item.2>` + "\n" +
+	       `<item.3> ::= "" | "!"`
+	if dg.String() != ref {
+		t.Errorf("Expected desugared grammar:\n%s\nReturned:\n%s",
+		         ref, dg.String())
+	}
+
+	bounded := bnf.Grammar{Rules: []bnf.Rule{{
+		Head: bnf.SymbolNonTerminal{Name: "a"},
+		Tail: bnf.Substitution{Sequences: []bnf.Sequence{{
+			Symbols: []bnf.Symbol{bnf.SymbolRepetition{
+				Symbol: bnf.SymbolTerminal{Name: "x"},
+				Min: 1,
+				Max: 3,
+			}},
+		}}},
+	}}}
+
+	dg, err = bnf.Desugar(bounded)
+	if err != nil {
+		t.Fatalf("Failed to desugar grammar: %s", err.Error())
+	}
+
+	ref = `<a> ::= "x" <a.1>` + "\n" +
+	      `<a.1> ::= "" | "x" <a.2>` + "\n" +
+	      `<a.2> ::= "" | "x"`
+	if dg.String() != ref {
+		t.Errorf("Expected desugared grammar:\n%s\nReturned:\n%s",
+		         ref, dg.String())
+	}
+
+	bounded.Rules[0].Tail.Sequences[0].Symbols[0] = bnf.SymbolRepetition{
+		Symbol: bnf.SymbolTerminal{Name: "x"},
+		Min: 2,
+		Max: 1,
+	}
+	_, err = bnf.Desugar(bounded)
+	if err == nil {
+		t.Errorf("Expected error on invalid repetition bounds")
+	}
+}
+
+func TestEBNFParserParse(t *testing.T) {
+
+	g := tc.MustGrammar(t, ebnfGrammar)
+	p := tc.MustParser(t, ebnfGrammar)
+
+	for _, src := range []string{"[]", "[a]", "[ab!,[],b,[a,bb]]"} {
+		tree, _, err := p.Parse(src)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %s", src, err.Error())
+		}
+
+		for _, v := range validate.Validate(tree, g, src) {
+			t.Errorf("Unexpected violation in tree of %q: %s", src, v)
+		}
+	}
+
+	for _, src := range []string{"[,]", "[a,]", "[!]", "[a!!]"} {
+		_, _, err := p.Parse(src)
+		if err == nil {
+			t.Errorf("Expected error parsing %q", src)
+		}
+	}
+
+	src := "[ab!,[]]"
+	res, err := parser.ParseToResult(p, src)
+	if err != nil {
+		t.Fatalf("Failed to parse %q: %s", src, err.Error())
+	}
+
+	names := res.Symbols().Map()
+	root, err := transform.Apply(res.Root, names, src, transform.Config{
+		InlineGenerated: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to transform: %s", err.Error())
+	}
+
+	sb := bytes.Buffer{}
+	cst.Fprint(&sb, root, names, src, cst.PrintOptions{Compact: true})
+	ref := "0 list 0:8 \"[ab!,[]]\"\n" +
+	       "1 _literal 0:1 \"[\"\n" +
+	       "1 item 1:4 \"ab!\"\n" +
+	       "2 _literal 1:2 \"a\"\n" +
+	       "2 _literal 2:3 \"b\"\n" +
+	       "2 _literal 3:4 \"!\"\n" +
+	       "1 _literal 4:5 \",\"\n" +
+	       "1 item 5:7 \"[]\"\n" +
+	       "2 list 5:7 \"[]\"\n" +
+	       "3 _literal 5:6 \"[\"\n" +
+	       "3 _literal 6:7 \"]\"\n" +
+	       "1 _literal 7:8 \"]\"\n"
+	if sb.String() != ref {
+		t.Errorf("Expected:\n%s\nReturned:\n%s", ref, sb.String())
+	}
+}
@@ -0,0 +1,78 @@
+package bnf_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/TooManySugar/ll1parser/pkg/parser"
+	tc "github.com/TooManySugar/ll1parser/test/testcommon"
+)
+
+func TestSyntaxErrorPosition(t *testing.T) {
+
+	p := tc.MustParser(t, sumGrammar)
+
+	_, _, err := p.Parse("12+3+")
+
+	var se *parser.SyntaxError
+	if !errors.As(err, &se) {
+		t.Fatalf("Expected SyntaxError, got %v", err)
+	}
+
+	if se.Offset != 5 {
+		t.Errorf("Expected offset 5, got %d", se.Offset)
+	}
+
+	refRules := "[sum sum-tail sum-tail num]"
+	if rules := fmt.Sprint(se.Rules); rules != refRules {
+		t.Errorf("Expected rules %s, got %s", refRules, rules)
+	}
+}
+
+func TestErrorPrinterFprint(t *testing.T) {
+
+	p := tc.MustParser(t, `<lines> ::= <line> <lines-tail>` + "\n" +
+	                      `<lines-tail> ::= "" | "\n" <line> <lines-tail>` + "\n" +
+	                      `<line> ::= "\t" "ab" | "ab"`)
+
+	src := "ab\n\tab\n\tax"
+
+	_, _, err := p.Parse(src)
+	if err == nil {
+		t.Fatalf("Expected parsing error")
+	}
+
+	sb := bytes.Buffer{}
+	ep := parser.ErrorPrinter{Filename: "in.txt"}
+	if werr := ep.Fprint(&sb, err, []byte(src)); werr != nil {
+		t.Fatalf("Failed to print error: %s", werr.Error())
+	}
+
+	ref := "in.txt:3:3: error: expected char 'b' (98), got 'x' (120)\n" +
+	       " 3 | \tax\n" +
+	       "   | \t ^\n" +
+	       "in <lines> > <lines-tail> > <lines-tail> > <line>\n"
+
+	if sb.String() != ref {
+		t.Errorf("Expected:\n%s\nReturned:\n%s", ref, sb.String())
+	}
+
+	sb.Reset()
+	ep = parser.ErrorPrinter{HideRules: true}
+	ep.Fprint(&sb, errors.New("empty source"), nil)
+	if sb.String() != "error: empty source\n" {
+		t.Errorf("Expected plain error, got %q", sb.String())
+	}
+
+	// offset out of source is clamped to it's bounds
+	sb.Reset()
+	ep.Fprint(&sb, &parser.SyntaxError{Offset: -3, Msg: "bad"}, []byte("ab"))
+	ref = "1:1: error: bad\n" +
+	      " 1 | ab\n" +
+	      "   | ^\n"
+	if sb.String() != ref {
+		t.Errorf("Expected:\n%s\nReturned:\n%s", ref, sb.String())
+	}
+}
@@ -0,0 +1,211 @@
+package bnf_test
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+
+	"github.com/TooManySugar/ll1parser/pkg/bnf"
+	"github.com/TooManySugar/ll1parser/pkg/bnf/abnf"
+	"github.com/TooManySugar/ll1parser/pkg/bnf/format"
+	"github.com/TooManySugar/ll1parser/pkg/bnf/tablegen"
+	"github.com/TooManySugar/ll1parser/pkg/parser"
+	tc "github.com/TooManySugar/ll1parser/test/testcommon"
+)
+
+func TestFormatGrammar(t *testing.T) {
+
+	g := tc.MustGrammar(t,
+		`<list> ::= <digit> ("," <digit>)* | ""` + "\n" +
+		`<digit> ::= "0" | "1" | "2" | "3" | "4" | "5" | "6" | "7" | "8"`)
+
+	ref := `<list>  ::= <digit> ("," <digit>)* | ""` + "\n" +
+	       `<digit> ::= "0" | "1" | "2" | "3" | "4" | "5"` + "\n" +
+	       `          | "6" | "7" | "8"`
+	res := format.Grammar(g, format.Options{Width: 45})
+	if res != ref {
+		t.Errorf("Expected formatted grammar:\n%s\nReturned:\n%s", ref, res)
+	}
+
+	// alternative longer than width is not split
+	ref = `<list> ::= <digit> ("," <digit>)*` + "\n" +
+	      `         | ""`
+	res = format.Grammar(bnf.Grammar{Rules: g.Rules[:1]},
+	                     format.Options{Width: 10})
+	if res != ref {
+		t.Errorf("Expected formatted grammar:\n%s\nReturned:\n%s", ref, res)
+	}
+}
+
+func TestFormatGrammarRepetitions(t *testing.T) {
+
+	g, err := abnf.Parse(
+		"code  = 2*3digit \"-\" 2*digit [\"-\" 3( \"a\" / \"b\" )]\n" +
+		"digit = %x30-39")
+	if err != nil {
+		t.Fatalf("Failed to parse ABNF: %s", err.Error())
+	}
+
+	ref := `<code> ::= <digit> <digit> <digit>? "-" <digit> <digit>+` +
+	       ` ("-" ("a"i | "b"i) ("a"i | "b"i) ("a"i | "b"i))?`
+	res := format.Grammar(bnf.Grammar{Rules: g.Rules[:1]},
+	                      format.Options{Width: 120})
+	if res != ref {
+		t.Errorf("Expected formatted grammar:\n%s\nReturned:\n%s", ref, res)
+	}
+
+	// formatted grammar is parsed back to grammar of the same language
+	table, names, err := tablegen.FromGrammar(*g)
+	if err != nil {
+		t.Fatalf("Failed to build parser table: %s", err.Error())
+	}
+	p := parser.NewLL1Parser(*table, *names)
+	formatted := tc.MustParser(t, format.Grammar(*g, format.Options{}))
+
+	srcs := []string{"12-34", "123-4567-aba", "1-23", "1234-56", "12-3",
+	                 "12-34-ab", "12-34-abab"}
+	for _, src := range srcs {
+		_, _, err := p.Parse(src)
+		_, _, formattedErr := formatted.Parse(src)
+		if (err == nil) != (formattedErr == nil) {
+			t.Errorf("%q: parsed by ABNF grammar: %t, by formatted one: %t",
+			         src, err == nil, formattedErr == nil)
+		}
+	}
+
+	// {m,n} is expanded to nested optional symbols
+	rep := bnf.Grammar{Rules: []bnf.Rule{{
+		Head: bnf.SymbolNonTerminal{Name: "a"},
+		Tail: bnf.Substitution{Sequences: []bnf.Sequence{{
+			Symbols: []bnf.Symbol{bnf.SymbolRepetition{
+				Symbol: bnf.SymbolTerminal{Name: "a"}, Min: 1, Max: 4,
+			}},
+		}}},
+	}}}
+	ref = `<a> ::= "a" ("a" ("a" "a"?)?)?`
+	if res := format.Grammar(rep, format.Options{}); res != ref {
+		t.Errorf("Expected formatted grammar:\n%s\nReturned:\n%s", ref, res)
+	}
+}
+
+func TestFormatSource(t *testing.T) {
+
+	src := "; digits\r\n" +
+	       "\n" +
+	       "<list> ::= <digit>\n" +
+	       "\t( \",\" <digit> )*   # more digits\n" +
+	       "<digit>::=\"0\"|\"1\" ; first\n" +
+	       "  | \"2\"\n" +
+	       "\n" +
+	       "\n" +
+	       "; trailing comment\n"
+
+	ref := "; digits\n" +
+	       "\n" +
+	       "<list>  ::= <digit> (\",\" <digit>)* # more digits\n" +
+	       "; first\n" +
+	       "<digit> ::= \"0\" | \"1\" | \"2\"\n" +
+	       "\n" +
+	       "; trailing comment\n"
+
+	res, err := format.Source([]byte(src), format.Options{})
+	if err != nil {
+		t.Fatalf("Failed to format: %s", err.Error())
+	}
+	if string(res) != ref {
+		t.Errorf("Expected formatted source:\n%s\nReturned:\n%s", ref, res)
+	}
+
+	sources := []string{src, dialectGrammar, bnf.SelfGrammar().String()}
+	for _, src := range sources {
+		res, err := format.Source([]byte(src), format.Options{Width: 40})
+		if err != nil {
+			t.Fatalf("Failed to format: %s", err.Error())
+		}
+
+		again, err := format.Source(res, format.Options{Width: 40})
+		if err != nil {
+			t.Fatalf("Failed to format formatted source: %s", err.Error())
+		}
+		if string(again) != string(res) {
+			t.Errorf("Formatting is not idempotent:\n%s\nReformatted:\n%s",
+			         res, again)
+		}
+
+		g, formatted := tc.MustGrammar(t, src), tc.MustGrammar(t, string(res))
+		if g.String() != formatted.String() {
+			t.Errorf("Formatted source parsed to other grammar:\n%s\n" +
+			         "Expected:\n%s", formatted.String(), g.String())
+		}
+	}
+}
+
+func TestFormatSourceImports(t *testing.T) {
+
+	// files of loader test are formatted
+	err := fs.WalkDir(moduleFiles, "testdata/modules",
+	                  func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		src, err := fs.ReadFile(moduleFiles, name)
+		if err != nil {
+			return err
+		}
+		res, err := format.Source(src, format.Options{})
+		if err != nil {
+			t.Errorf("Failed to format %s: %s", name, err.Error())
+			return nil
+		}
+		if string(res) != string(src) {
+			t.Errorf("Expected %s to be formatted as is, returned:\n%s",
+			         name, res)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to read modules: %s", err.Error())
+	}
+
+	src := "; imports\n" +
+	       "import  \"a.bnf\" ; first\r\n" +
+	       "\n" +
+	       "import \"b.bnf\"\n" +
+	       "\n" +
+	       "\n" +
+	       "<a>::=\"a\"|<b> ; rule\n"
+
+	ref := "; imports\n" +
+	       "import  \"a.bnf\" ; first\r\n" +
+	       "\n" +
+	       "import \"b.bnf\"\n" +
+	       "\n" +
+	       "<a> ::= \"a\" | <b> ; rule\n"
+
+	res, err := format.Source([]byte(src), format.Options{})
+	if err != nil {
+		t.Fatalf("Failed to format: %s", err.Error())
+	}
+	if string(res) != ref {
+		t.Errorf("Expected formatted source:\n%s\nReturned:\n%s", ref, res)
+	}
+
+	// file with only imports
+	src = "import \"a.bnf\"\n; nothing else\n\n"
+	res, err = format.Source([]byte(src), format.Options{})
+	if err != nil {
+		t.Fatalf("Failed to format: %s", err.Error())
+	}
+	if string(res) != src[:len(src) - 1] {
+		t.Errorf("Expected formatted source:\n%s\nReturned:\n%s",
+		         src[:len(src) - 1], res)
+	}
+
+	var se *parser.SyntaxError
+	_, err = format.Source([]byte("import a.bnf\n<a> ::= \"a\""),
+	                       format.Options{})
+	if !errors.As(err, &se) || se.Offset != 7 {
+		t.Errorf("Expected syntax error at offset 7, got %v", err)
+	}
+}
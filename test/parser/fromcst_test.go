@@ -0,0 +1,68 @@
+package bnf_test
+
+import (
+	"testing"
+
+	"github.com/TooManySugar/ll1parser/pkg/bnf/fromcst"
+	tc "github.com/TooManySugar/ll1parser/test/testcommon"
+)
+
+// Syntax with node per rule, alternative and symbol, as bindings of
+// RuleType were written for
+const ruleNodeGrammar = `
+<syntax>    ::= <rule> <rules>
+<rules>     ::= "" | <rule> <rules>
+<rule>      ::= "<" <name> ">" "=" <expr> ";"
+<expr>      ::= <seq> <expr-tail>
+<expr-tail> ::= "" | "|" <seq> <expr-tail>
+<seq>       ::= <sym> <seq-tail>
+<seq-tail>  ::= "" | <sym> <seq-tail>
+<sym>       ::= "'" <text> "'" | "<" <name> ">"
+<text>      ::= <char> <text-tail>
+<text-tail> ::= "" | <char> <text-tail>
+<char>      ::= "a" | "b" | "c" | "x" | "y" | "z"
+<name>      ::= <char> <text-tail>
+`
+
+func TestFromCSTRuleTypeBindings(t *testing.T) {
+
+	p := tc.MustParser(t, ruleNodeGrammar)
+
+	src := "<a>=<b>'xy'|'z';<bc>='c';"
+	tree, _, err := p.Parse(src)
+	if err != nil {
+		t.Fatalf("Failed to parse %q: %s", src, err.Error())
+	}
+
+	bindings := fromcst.BNFCSTtoASTBindings{
+		RuleType: 2,
+		RuleHeadType: 11,
+		RuleTailType: 3,
+		ExpressionSequencesType: 5,
+		SequencesSymbolType: 7,
+		SymbolTerminalTypes: []int{8},
+		SymbolNonTerminalType: 11,
+	}
+
+	g, err := bindings.ToAST(tree, src)
+	if err != nil {
+		t.Fatalf("Failed to convert tree: %s", err.Error())
+	}
+
+	ref := `<a> ::= <b> "xy" | "z"` + "\n" + `<bc> ::= "c"`
+	if g.String() != ref {
+		t.Errorf("Expected grammar string:\n%s\nReturned:\n%s",
+		         ref, g.String())
+	}
+
+	heads := bindings.RuleHeads(tree)
+	if len(heads) != 2 || heads[1].Pos() != 16 {
+		t.Errorf("Expected rule heads at 0 and 16, got %v", heads)
+	}
+
+	// bindings of neither way are rejected instead of reading nothing
+	_, err = fromcst.BNFCSTtoASTBindings{}.ToAST(tree, src)
+	if err == nil {
+		t.Errorf("Expected error on empty bindings")
+	}
+}
@@ -0,0 +1,174 @@
+package bnf_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/TooManySugar/ll1parser/pkg/bnf"
+	"github.com/TooManySugar/ll1parser/pkg/bnf/tablegen"
+	"github.com/TooManySugar/ll1parser/pkg/cst"
+	"github.com/TooManySugar/ll1parser/pkg/parser"
+)
+
+func TestReparseEqualToFullParse(t *testing.T) {
+
+	grammar := bnf.SelfGrammar()
+
+	table, tableNames, err := tablegen.FromGrammar(grammar)
+	if err != nil {
+		t.Fatal("Failed to parse grammar:", err.Error())
+	}
+
+	p := parser.NewLL1Parser(*table, *tableNames)
+
+	src := " <A-2-B> ::= \"A000123\" \"B\" | \"B\"| \"A\" |\"A\"|\"A\"   \n" +
+	       " <A2-B-2-B>::= <B> "
+
+	prev, err := parser.ParseToResult(p, src)
+	if err != nil {
+		t.Fatalf("Failed to parse input: %s", err.Error())
+	}
+
+	var edits []parser.Edit
+	for i := 0; i <= len(src); i++ {
+		for _, ins := range []string{"", "A", "\"", " ", "|", "<C>"} {
+			edits = append(edits, parser.Edit{Offset: i, Inserted: ins})
+			if i < len(src) {
+				edits = append(edits,
+				               parser.Edit{Offset: i, Deleted: 1, Inserted: ins})
+			}
+		}
+	}
+
+	for _, e := range edits {
+		res, reserr := parser.Reparse(p, prev, e)
+
+		edited := src[:e.Offset] + e.Inserted + src[e.Offset + e.Deleted:]
+		ref, referr := parser.ParseToResult(p, edited)
+
+		if (reserr == nil) != (referr == nil) {
+			t.Fatalf("Edit %+v: expected error %v, got %v", e, referr, reserr)
+		}
+		if referr != nil {
+			continue
+		}
+
+		if string(res.Src) != edited {
+			t.Fatalf("Edit %+v: expected source %q, got %q", e, edited, res.Src)
+		}
+
+		diffs := cst.Diff(ref.Root, res.Root, ref.Symbols().Map(),
+		                  edited, string(res.Src), cst.CompareOptions{})
+		if len(diffs) > 0 {
+			t.Fatalf("Edit %+v: trees differ\n%s",
+			         e, strings.Join(diffs, "\n"))
+		}
+	}
+
+	_, err = parser.Reparse(p, prev, parser.Edit{Offset: len(src), Deleted: 1})
+	if err == nil {
+		t.Errorf("Expected error on out of bounds edit")
+	}
+}
+
+func TestReparseChainedEdits(t *testing.T) {
+
+	table, tableNames, err := tablegen.FromGrammar(bnf.SelfGrammar())
+	if err != nil {
+		t.Fatal("Failed to parse grammar:", err.Error())
+	}
+	p := parser.NewLL1Parser(*table, *tableNames)
+
+	src := "<a> ::= \"a\" | <b>\n<b> ::= \"b\" <c>\n<c> ::= \"c\" | \"d\"\n"
+	res, err := parser.ParseToResult(p, src)
+	if err != nil {
+		t.Fatalf("Failed to parse input: %s", err.Error())
+	}
+
+	// rules after edited one are moved by each edit, back and forth
+	edits := []parser.Edit{
+		{Offset: 10, Inserted: "aa"},
+		{Offset: 10, Deleted: 2, Inserted: "b"},
+		{Offset: 10, Deleted: 1},
+		{Offset: 2, Inserted: "-x"},
+		{Offset: 9, Inserted: "  "},
+		{Offset: 9, Deleted: 2},
+		{Offset: 2, Deleted: 2},
+	}
+
+	for i, e := range edits {
+		res, err = parser.Reparse(p, res, e)
+		if err != nil {
+			t.Fatalf("Edit %d %+v: %s", i, e, err.Error())
+		}
+
+		ref, err := parser.ParseToResult(p, string(res.Src))
+		if err != nil {
+			t.Fatalf("Edit %d %+v: failed to parse %q: %s",
+			         i, e, res.Src, err.Error())
+		}
+
+		diffs := cst.Diff(ref.Root, res.Root, ref.Symbols().Map(),
+		                  string(ref.Src), string(res.Src),
+		                  cst.CompareOptions{})
+		if len(diffs) > 0 {
+			t.Fatalf("Edit %d %+v: trees differ\n%s",
+			         i, e, strings.Join(diffs, "\n"))
+		}
+	}
+
+	if string(res.Src) != src {
+		t.Errorf("Expected edits to restore source %q, got %q", src, res.Src)
+	}
+}
+
+// Reports whether a and b share childs, i.e. are the same subtree
+func sameSubtree(a cst.Node, b cst.Node) bool {
+	ac, bc := a.Childs(), b.Childs()
+	return len(ac) > 0 && len(ac) == len(bc) && &ac[0] == &bc[0]
+}
+
+func TestReparseReusesOldTree(t *testing.T) {
+
+	table, tableNames, err := tablegen.FromGrammar(bnf.SelfGrammar())
+	if err != nil {
+		t.Fatal("Failed to parse grammar:", err.Error())
+	}
+	p := parser.NewLL1Parser(*table, *tableNames)
+
+	src := "<a> ::= \"a\" | <b>\n<b> ::= \"b\"\n"
+	prev, err := parser.ParseToResult(p, src)
+	if err != nil {
+		t.Fatalf("Failed to parse input: %s", err.Error())
+	}
+
+	// child 1 of <syntax> is head of first rule, it is before the edit
+	head := prev.Root.Childs()[1]
+
+	res, err := parser.Reparse(p, prev, parser.Edit{Offset: 27, Inserted: "bb"})
+	if err != nil {
+		t.Fatalf("Failed to reparse: %s", err.Error())
+	}
+	if !sameSubtree(head, res.Root.Childs()[1]) {
+		t.Errorf("Expected rule head before edit to be reused")
+	}
+
+	// nothing encloses edit at start of source, so it is parsed again
+	res, err = parser.Reparse(p, prev, parser.Edit{Offset: 0, Inserted: " "})
+	if err != nil {
+		t.Fatalf("Failed to reparse: %s", err.Error())
+	}
+	if sameSubtree(head, res.Root.Childs()[1]) {
+		t.Errorf("Expected full parse on edit at start of source")
+	}
+
+	ref, err := parser.ParseToResult(p, string(res.Src))
+	if err != nil {
+		t.Fatalf("Failed to parse %q: %s", res.Src, err.Error())
+	}
+	diffs := cst.Diff(ref.Root, res.Root, ref.Symbols().Map(),
+	                  string(ref.Src), string(res.Src), cst.CompareOptions{})
+	if len(diffs) > 0 {
+		t.Errorf("Trees differ\n%s", strings.Join(diffs, "\n"))
+	}
+}
@@ -0,0 +1,112 @@
+package bnf_test
+
+import (
+	"embed"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/TooManySugar/ll1parser/pkg/bnf/loader"
+	"github.com/TooManySugar/ll1parser/pkg/bnf/tablegen"
+	"github.com/TooManySugar/ll1parser/pkg/parser"
+)
+
+//go:embed testdata/modules
+var moduleFiles embed.FS
+
+func TestLoaderLoad(t *testing.T) {
+
+	g, err := loader.Load(moduleFiles, "testdata/modules/main.bnf")
+	if err != nil {
+		t.Fatalf("Failed to load grammar: %s", err.Error())
+	}
+
+	ref := `<list> ::= <pair> ("," <ws> <pair>)*` + "\n" +
+	       `<pair> ::= <key> "=" <number>` + "\n" +
+	       `<ws> ::= (" " | "\t")*` + "\n" +
+	       `<key> ::= ("a" | "b" | "c")+` + "\n" +
+	       `<number> ::= <digit>+` + "\n" +
+	       `<digit> ::= "0" | "1" | "2" | "3" | "4" | "5" | "6" | "7" | ` +
+	                   `"8" | "9"`
+	if g.String() != ref {
+		t.Errorf("Expected grammar string:\n%s\nReturned:\n%s",
+		         ref, g.String())
+	}
+
+	positions := []string{
+		"testdata/modules/main.bnf:5:1",
+		"testdata/modules/main.bnf:6:1",
+		"testdata/modules/common.bnf:1:1",
+		"testdata/modules/common.bnf:2:1",
+		"testdata/modules/lex/number.bnf:3:1",
+		"testdata/modules/lex/number.bnf:4:1",
+	}
+	for i, ref := range positions {
+		if pos := g.Position(i).String(); pos != ref {
+			t.Errorf("Rule %d: expected position %s, got %s", i, ref, pos)
+		}
+	}
+
+	table, names, err := tablegen.FromGrammar(g.Grammar)
+	if err != nil {
+		t.Fatalf("Failed to build parser table: %s", err.Error())
+	}
+	_, _, err = parser.NewLL1Parser(*table, *names).Parse("a=1, bc=23")
+	if err != nil {
+		t.Errorf("Failed to parse with loaded grammar: %s", err.Error())
+	}
+}
+
+func TestLoaderErrors(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"cycle.bnf": {Data: []byte("import \"a/b.bnf\"\n<x> ::= \"x\"")},
+		"a/b.bnf": {Data: []byte("import \"../cycle.bnf\"\n<y> ::= \"y\"")},
+		"clash.bnf": {Data: []byte("import \"c.bnf\"\n<x> ::= \"x\"")},
+		"c.bnf": {Data: []byte("; common\n<z> ::= \"z\"\n<x> ::= \"y\"")},
+		"missing.bnf": {Data: []byte("import \"none.bnf\"\n<x> ::= \"x\"")},
+		"unquoted.bnf": {Data: []byte("import c.bnf\n<x> ::= \"x\"")},
+		"unterminated.bnf": {Data: []byte("import \"c.bnf\n<x> ::= \"x\"")},
+		"outside.bnf": {Data: []byte("import \"../c.bnf\"\n<x> ::= \"x\"")},
+		"syntax.bnf": {Data: []byte("import \"c.bnf\"\n<a> ::= \"x\" ]")},
+		"only-imports.bnf": {Data: []byte("import \"c.bnf\" ; shared\n")},
+	}
+
+	tests := []struct {
+		name string
+		err string
+	}{
+		{"cycle.bnf", "a/b.bnf:1:1: import cycle: cycle.bnf -> a/b.bnf -> " +
+		              "cycle.bnf"},
+		{"clash.bnf", "c.bnf:3:1: rule <x> is already defined at " +
+		              "clash.bnf:2:1"},
+		{"missing.bnf", "missing.bnf:1:1: open none.bnf: file does not exist"},
+		{"unquoted.bnf", "unquoted.bnf:1:8: expected quoted path after import"},
+		{"unterminated.bnf", "unterminated.bnf:1:8: unterminated import path"},
+		{"outside.bnf", "outside.bnf:1:1: invalid import path \"../c.bnf\""},
+	}
+
+	for _, test := range tests {
+		_, err := loader.Load(fsys, test.name)
+		if err == nil || err.Error() != test.err {
+			t.Errorf("Load %s: expected error %q, got %v",
+			         test.name, test.err, err)
+		}
+	}
+
+	_, err := loader.Load(fsys, "syntax.bnf")
+	var syntaxErr *parser.SyntaxError
+	if !errors.As(err, &syntaxErr) || syntaxErr.Offset != 27 {
+		t.Errorf("Expected syntax error at offset 27, got %v", err)
+	}
+
+	g, err := loader.Load(fsys, "only-imports.bnf")
+	if err != nil {
+		t.Fatalf("Failed to load grammar: %s", err.Error())
+	}
+	ref := `<z> ::= "z"` + "\n" + `<x> ::= "y"`
+	if g.String() != ref {
+		t.Errorf("Expected grammar string:\n%s\nReturned:\n%s",
+		         ref, g.String())
+	}
+}
@@ -0,0 +1,70 @@
+package bnf_test
+
+import (
+	"testing"
+
+	"github.com/TooManySugar/ll1parser/pkg/bnf/tablegen"
+	"github.com/TooManySugar/ll1parser/pkg/parser"
+	tc "github.com/TooManySugar/ll1parser/test/testcommon"
+	tg "github.com/TooManySugar/ll1parser/test/testgrammars"
+)
+
+func TestParserParseResult(t *testing.T) {
+
+	grammar := tg.ResolvedLRecursive()
+
+	table, tableNames, err := tablegen.FromGrammar(grammar)
+	if err != nil {
+		t.Fatal("Failed to parse grammar:", err.Error())
+	}
+
+	p := parser.NewLL1Parser(*table, *tableNames)
+
+	src := "_BB"
+
+	res, err := parser.ParseToResult(p, src)
+	if err != nil {
+		t.Fatalf("Failed to parse input: %s", err.Error())
+	}
+
+	if string(res.Src) != src {
+		t.Errorf("Expected source %q, got %q", src, string(res.Src))
+	}
+
+	if res.Text(res.Root) != src {
+		t.Errorf("Expected root text %q, got %q", src, res.Text(res.Root))
+	}
+
+	if res.Name(res.Root) != "T" {
+		t.Errorf("Expected root name T, got %s", res.Name(res.Root))
+	}
+
+	ta := res.Root.Childs()[1]
+	if res.Name(ta) != "Ta" || res.Text(ta) != "BB" {
+		t.Errorf("Expected <Ta> `BB`, got <%s> `%s`", res.Name(ta), res.Text(ta))
+	}
+
+	for name, ref := range map[string]int{"T": 0, "Ta": 1, "_literal": -1} {
+		typ, ok := res.TypeOf(name)
+		if !ok || typ != ref {
+			t.Errorf("TypeOf(%s): expected %d, got %d, %v", name, ref, typ, ok)
+		}
+	}
+
+	if _, ok := res.TypeOf("B"); ok {
+		t.Errorf("TypeOf(B) must not be found")
+	}
+
+	_, resnt, err := p.Parse(src)
+	if err != nil {
+		t.Fatalf("Failed to parse input: %s", err.Error())
+	}
+
+	tc.MapsIntStringMustBeEqual(t, *resnt, res.Symbols().Map())
+
+	// only parsers of NewLL1Parser produce ParseResult
+	wrapped := struct{ parser.LL1Parser }{p}
+	if _, err := parser.ParseToResult(wrapped, src); err == nil {
+		t.Errorf("Expected error on parser not created by NewLL1Parser")
+	}
+}
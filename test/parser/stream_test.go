@@ -0,0 +1,89 @@
+package bnf_test
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/TooManySugar/ll1parser/pkg/bnf/tablegen"
+	"github.com/TooManySugar/ll1parser/pkg/parser"
+	tg "github.com/TooManySugar/ll1parser/test/testgrammars"
+)
+
+func TestDocumentScanner(t *testing.T) {
+
+	grammar := tg.ResolvedLRecursive()
+
+	table, tableNames, err := tablegen.FromGrammar(grammar)
+	if err != nil {
+		t.Fatal("Failed to parse grammar:", err.Error())
+	}
+
+	p := parser.NewLL1Parser(*table, *tableNames)
+
+	ref := []string{"_B", "_BBB", "_", "_BB"}
+	refRanges := [][2]int{{0, 2}, {2, 6}, {6, 7}, {7, 10}}
+
+	// small buffer forces documents to be split between reads
+	s := parser.NewDocumentScanner(p, strings.NewReader(strings.Join(ref, "")))
+	s.Buffer(make([]byte, 2), 64)
+
+	i := 0
+	for s.Scan() {
+		if i >= len(ref) {
+			t.Fatalf("Unexpected document %q", string(s.Result().Src))
+		}
+
+		res := s.Result()
+		if string(res.Src) != ref[i] {
+			t.Errorf("Document %d: expected %q, got %q", i, ref[i], res.Src)
+		}
+		if res.Text(res.Root) != ref[i] {
+			t.Errorf("Document %d: expected root text %q, got %q",
+			         i, ref[i], res.Text(res.Root))
+		}
+
+		start, end := s.Range()
+		if start != refRanges[i][0] || end != refRanges[i][1] {
+			t.Errorf("Document %d: expected range %v, got [%d %d]",
+			         i, refRanges[i], start, end)
+		}
+		i++
+	}
+
+	if s.Err() != nil {
+		t.Fatalf("Unexpected error: %s", s.Err().Error())
+	}
+
+	if i != len(ref) {
+		t.Errorf("Expected %d documents, got %d", len(ref), i)
+	}
+}
+
+func TestSplitFunc(t *testing.T) {
+
+	grammar := tg.ResolvedLRecursive()
+
+	table, tableNames, err := tablegen.FromGrammar(grammar)
+	if err != nil {
+		t.Fatal("Failed to parse grammar:", err.Error())
+	}
+
+	p := parser.NewLL1Parser(*table, *tableNames)
+
+	s := bufio.NewScanner(strings.NewReader("_BB_B!_"))
+	s.Split(parser.SplitFunc(p))
+
+	var tokens []string
+	for s.Scan() {
+		tokens = append(tokens, s.Text())
+	}
+
+	if strings.Join(tokens, " ") != "_BB _B" {
+		t.Errorf("Expected tokens [_BB _B], got %v", tokens)
+	}
+
+	if s.Err() == nil {
+		t.Errorf("Expected error on `!`")
+	}
+}
@@ -0,0 +1,189 @@
+package bnf_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/TooManySugar/ll1parser/pkg/bnf"
+	"github.com/TooManySugar/ll1parser/pkg/bnf/validate"
+	"github.com/TooManySugar/ll1parser/pkg/cst"
+	"github.com/TooManySugar/ll1parser/pkg/parser"
+	tc "github.com/TooManySugar/ll1parser/test/testcommon"
+)
+
+func TestValidateParsedTrees(t *testing.T) {
+
+	tests := []struct {
+		grammar string
+		srcs []string
+	}{
+		{sumGrammar, []string{"1", "1+2", "10+200+3"}},
+		{caseInsensitiveGrammar, []string{"select x", "SeLeCt Y", "drop y"}},
+	}
+
+	for _, test := range tests {
+		g := tc.MustGrammar(t, test.grammar)
+		p := tc.MustParser(t, test.grammar)
+
+		for _, src := range test.srcs {
+			tree, _, err := p.Parse(src)
+			if err != nil {
+				t.Fatalf("Failed to parse %q: %s", src, err.Error())
+			}
+
+			for _, v := range validate.Validate(tree, g, src) {
+				t.Errorf("Unexpected violation in tree of %q: %s", src, v)
+			}
+		}
+	}
+}
+
+func TestValidateViolations(t *testing.T) {
+
+	g := tc.MustGrammar(t, sumGrammar)
+
+	// rule types in order of sumGrammar
+	const (
+		sum = iota
+		sumTail
+		num
+		numTail
+		digit
+	)
+
+	n := cst.NewNode
+	lit := func(start int) cst.Node {
+		return n(parser.BuiltinTerminal, start, start + 1, nil)
+	}
+	empty := func(nType int, pos int) cst.Node {
+		return n(nType, pos, pos,
+		         []cst.Node{n(parser.BuiltinNothing, pos, pos, nil)})
+	}
+	number := func(start int) cst.Node {
+		return n(num, start, start + 1, []cst.Node{
+			n(digit, start, start + 1, []cst.Node{lit(start)}),
+			empty(numTail, start + 1),
+		})
+	}
+
+	src := "1+2"
+	tests := []struct {
+		tree cst.Node
+		ref []string
+	}{
+		{
+			n(sum, 0, 3, []cst.Node{
+				number(0),
+				n(sumTail, 1, 3, []cst.Node{
+					lit(1), number(2), empty(sumTail, 3),
+				}),
+			}),
+			nil,
+		},
+		{
+			n(sum, 0, 3, []cst.Node{
+				number(0),
+				n(sumTail, 1, 3, []cst.Node{
+					number(1), number(2), empty(sumTail, 3),
+				}),
+			}),
+			[]string{
+				"/sum[0]/sum-tail[1]: childs `<num> <num> <sum-tail>` " +
+				"match no alternative of <sum-tail>",
+				"/sum[0]/sum-tail[1]/num[0]/digit[0]: childs `\"+\"` " +
+				"match no alternative of <digit>",
+			},
+		},
+		{
+			n(sum, 0, 3, []cst.Node{empty(num, 0), empty(sumTail, 0)}),
+			[]string{
+				"/sum[0]: childs end at 0, expected 3",
+				"/sum[0]/num[0]: rule <num> can't be empty",
+			},
+		},
+		{
+			n(sum, 0, 3, []cst.Node{number(0), empty(sumTail, 2)}),
+			[]string{
+				"/sum[0]: child 1 starts at 2, expected 1",
+				"/sum[0]: childs end at 2, expected 3",
+			},
+		},
+		{
+			n(sum, 0, 4, []cst.Node{number(0), n(7, 1, 4, nil)}),
+			[]string{
+				"/sum[0]: span [0:4] is out of source of length 3",
+			},
+		},
+		{
+			n(sum, 0, 3, []cst.Node{number(0), n(7, 1, 3, nil)}),
+			[]string{
+				"/sum[0]: childs `<num> <Unknown_7>` " +
+				"match no alternative of <sum>",
+				"/sum[0]/Unknown_7[1]: unknown node type 7",
+			},
+		},
+		{
+			n(digit, 0, 1, []cst.Node{n(parser.BuiltinTerminal, 0, 1,
+			                            []cst.Node{lit(0)})}),
+			[]string{
+				"/digit[0]/_literal[0]: _literal node has childs",
+			},
+		},
+	}
+
+	for i, test := range tests {
+		var res []string
+		for _, v := range validate.Validate(test.tree, g, src) {
+			res = append(res, v.Error())
+		}
+		if strings.Join(res, "\n") != strings.Join(test.ref, "\n") {
+			t.Errorf("TEST %d: expected:\n%s\nreturned:\n%s", i,
+			         strings.Join(test.ref, "\n"), strings.Join(res, "\n"))
+		}
+	}
+
+	// grammar which can't be desugared is reported at root
+	bad := bnf.Grammar{Rules: []bnf.Rule{{
+		Head: bnf.SymbolNonTerminal{Name: "a"},
+		Tail: bnf.Substitution{Sequences: []bnf.Sequence{{
+			Symbols: []bnf.Symbol{bnf.SymbolRepetition{
+				Symbol: bnf.SymbolTerminal{Name: "a"}, Min: 2, Max: 1,
+			}},
+		}}},
+	}}}
+	root := lit(0)
+	violations := validate.Validate(root, bad, src)
+	if len(violations) != 1 || violations[0].Node.Pos() != root.Pos() ||
+	   !strings.HasPrefix(violations[0].Error(), "/: can't desugar grammar") {
+		t.Errorf("Expected desugaring violation at root, got %v", violations)
+	}
+}
+
+func TestValidateIndirectlyEmptyRule(t *testing.T) {
+
+	const grammar = "<a> ::= <b>\n<b> ::= \"\" | \"b\""
+	g := tc.MustGrammar(t, grammar)
+	p := tc.MustParser(t, grammar)
+
+	// parsed tree keeps <b> between <a> and _nothing
+	tree, _, err := p.Parse("")
+	if err != nil {
+		t.Fatalf("Failed to parse empty source: %s", err.Error())
+	}
+	for _, v := range validate.Validate(tree, g, "") {
+		t.Errorf("Unexpected violation in parsed tree: %s", v)
+	}
+
+	// <a> can derive empty string only through <b>
+	n := cst.NewNode
+	root := n(0, 0, 0, []cst.Node{n(parser.BuiltinNothing, 0, 0, nil)})
+
+	var res []string
+	for _, v := range validate.Validate(root, g, "") {
+		res = append(res, v.Error())
+	}
+	ref := "/a[0]: rule <a> can't be empty"
+	if strings.Join(res, "\n") != ref {
+		t.Errorf("Expected:\n%s\nreturned:\n%s", ref, strings.Join(res, "\n"))
+	}
+}
@@ -0,0 +1,168 @@
+package bnf_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/TooManySugar/ll1parser/pkg/bnf/tablegen"
+	"github.com/TooManySugar/ll1parser/pkg/bnf/w3cebnf"
+	"github.com/TooManySugar/ll1parser/pkg/parser"
+)
+
+const w3cebnfGrammar =
+	"/* key value list */\n" +
+	"list   ::= pair (',' pair)*\n" +
+	"pair   ::= Name '=' (Number | Flag)\r\n" +
+	"Name   ::= [a-c_-]+\n" +
+	"Number ::= Digit - '0' Digit?  /* no leading zero */\n" +
+	"Digit  ::= [0-4]\n" +
+	"         | #x35\n" +
+	"Flag   ::= \"yes\" | 'no'? [ vc: Known Flag ]"
+
+func TestW3CEBNFGrammar(t *testing.T) {
+
+	g, err := w3cebnf.Parse(w3cebnfGrammar)
+	if err != nil {
+		t.Fatalf("Failed to parse W3C EBNF: %s", err.Error())
+	}
+
+	ref := `<list> ::= <pair> ("," <pair>)*` + "\n" +
+	       `<pair> ::= <Name> "=" (<Number> | <Flag>)` + "\n" +
+	       `<Name> ::= ("-" | "_" | "a" | "b" | "c")+` + "\n" +
+	       `<Number> ::= ("1" | "2" | "3" | "4" | "5") <Digit>?` + "\n" +
+	       `<Digit> ::= "0" | "1" | "2" | "3" | "4" | "5"` + "\n" +
+	       `<Flag> ::= "yes" | "no"?`
+	if g.String() != ref {
+		t.Errorf("Expected grammar string:\n%s\nReturned:\n%s",
+		         ref, g.String())
+	}
+}
+
+func TestW3CEBNFParserParse(t *testing.T) {
+
+	g, err := w3cebnf.Parse(w3cebnfGrammar)
+	if err != nil {
+		t.Fatalf("Failed to parse W3C EBNF: %s", err.Error())
+	}
+
+	table, names, err := tablegen.FromGrammar(*g)
+	if err != nil {
+		t.Fatalf("Failed to build parser table: %s", err.Error())
+	}
+	p := parser.NewLL1Parser(*table, *names)
+
+	tests := []struct {
+		src string
+		ok bool
+	}{
+		{"a=12,b-c=yes,_=", true},
+		{"a=no", true},
+		{"a=0", false},
+		{"a=16", false},
+		{"d=1", false},
+	}
+
+	for _, test := range tests {
+		_, _, err := p.Parse(test.src)
+		if (err == nil) != test.ok {
+			t.Errorf("Parse %q: expected success %t, got error %v",
+			         test.src, test.ok, err)
+		}
+	}
+}
+
+func TestW3CEBNFErrors(t *testing.T) {
+
+	tests := []struct {
+		src string
+		err string
+	}{
+		{"a ::= b* - 'x'\nb ::= 'y'", "1:7: difference b* - 'x' can't be " +
+		                              "desugared: b* is not a character set"},
+		{"a ::= 'x' - b\nb ::= 'x' 'y'", "1:7: difference 'x' - b can't be " +
+		                                 "desugared: b is not a character set"},
+		{"a ::= 'a' - 'a'", "1:7: 'a' - 'a' matches no characters"},
+		{"a ::= 'x' -", "1:11: - has no right operand"},
+		{"a ::= - 'x'", "1:7: - has no left operand"},
+		{"a ::= #x100", "1:7: character #x100 is out of byte range"},
+		{"a ::= [#x100]", "1:7: [#x100] matches no characters"},
+		{"a ::= [#x100] | [#x200-#x300]", "1:7: [#x100] matches no characters"},
+		{"a ::= [#x110000]", "1:8: character #x110000 is out of Unicode " +
+		                     "range"},
+		{"a ::= [#x]", "1:8: character code #x has no hex digits"},
+		{"a ::= 'a' #x", "1:11: character code #x has no hex digits"},
+		{"a ::= #x0", "1:7: zero byte marks end of input and can't be " +
+		              "matched"},
+		{"a ::= [z-a]", "1:8: range z-a is empty"},
+		{"a ::= b", "1:7: rule b is not defined"},
+		{"a ::= 'x'\na ::= 'y'", "2:1: rule a is already defined"},
+		{"a ::=\nb ::= 'x'", "1:1: rule a is empty"},
+		{"a ::= 'x' | | 'y'", "1:13: empty alternative"},
+		{"a ::= 'x' |", "1:12: empty alternative"},
+		{"a ::= ('x' b ::= 'y')", "1:12: unexpected rule head b in group"},
+	}
+
+	for _, test := range tests {
+		_, err := w3cebnf.Parse(test.src)
+		if err == nil || err.Error() != test.err {
+			t.Errorf("Parse %q: expected error %q, got %v",
+			         test.src, test.err, err)
+		}
+	}
+
+	_, err := w3cebnf.Parse("a ::= 'x' [^]")
+	var syntaxErr *parser.SyntaxError
+	if !errors.As(err, &syntaxErr) || syntaxErr.Offset != 12 {
+		t.Errorf("Expected syntax error at offset 12, got %v", err)
+	}
+}
+
+func TestW3CEBNFCharacters(t *testing.T) {
+
+	tests := []struct {
+		grammar string
+		srcs []string
+		bad []string
+	}{
+		// character ranges are clamped to bytes
+		{
+			"Text ::= Char+\n" +
+			"Char ::= #x9 | #xA | #xD | [#x20-#xD7FF] | [#xE000-#xFFFD]\n" +
+			"       | [#x10000-#x10FFFF]",
+			[]string{"text\twith tab\r\n", "caf\xc3\xa9\xff"},
+			[]string{"\x01", "a\x1fb"},
+		},
+		// non-ASCII characters of class are it's bytes
+		{
+			"Word   ::= Letter+\n" +
+			"Letter ::= [é#] - #x2028",
+			[]string{"é#", "\xa9\xc3"},
+			[]string{"e", "é-"},
+		},
+	}
+
+	for _, test := range tests {
+		g, err := w3cebnf.Parse(test.grammar)
+		if err != nil {
+			t.Fatalf("Failed to parse W3C EBNF: %s", err.Error())
+		}
+		table, names, err := tablegen.FromGrammar(*g)
+		if err != nil {
+			t.Fatalf("Failed to build parser table: %s", err.Error())
+		}
+		p := parser.NewLL1Parser(*table, *names)
+
+		for _, src := range test.srcs {
+			_, _, err := p.Parse(src)
+			if err != nil {
+				t.Errorf("Failed to parse %q: %s", src, err.Error())
+			}
+		}
+		for _, src := range test.bad {
+			_, _, err := p.Parse(src)
+			if err == nil {
+				t.Errorf("Expected error on %q", src)
+			}
+		}
+	}
+}
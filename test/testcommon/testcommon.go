@@ -1,11 +1,64 @@
 package testcommon
 
 import (
+	"fmt"
 	"io"
 	"testing"
 	"bytes"
+
+	"github.com/TooManySugar/ll1parser/pkg/bnf"
+	"github.com/TooManySugar/ll1parser/pkg/bnf/fromcst"
+	"github.com/TooManySugar/ll1parser/pkg/bnf/tablegen"
+	"github.com/TooManySugar/ll1parser/pkg/parser"
 )
 
+// Builds grammar from it's BNF text, fails test only if BNF parser table
+// could not be built
+func Grammar(t *testing.T, bnfText string) (*bnf.Grammar, error) {
+	t.Helper()
+
+	table, names, err := tablegen.FromGrammar(bnf.SelfGrammar())
+	if err != nil {
+		t.Fatalf("Failed to build BNF parser table: %s", err.Error())
+	}
+
+	tree, _, err := parser.NewLL1Parser(*table, *names).Parse(bnfText)
+	if err != nil {
+		return nil, fmt.Errorf("parse BNF text: %w", err)
+	}
+
+	g, err := fromcst.SelfCSTtoASTBindings().ToAST(tree, bnfText)
+	if err != nil {
+		return nil, fmt.Errorf("build grammar from BNF CST: %w", err)
+	}
+
+	return g, nil
+}
+
+// Builds grammar from it's BNF text, fails test on error
+func MustGrammar(t *testing.T, bnfText string) bnf.Grammar {
+	t.Helper()
+
+	g, err := Grammar(t, bnfText)
+	if err != nil {
+		t.Fatalf("Failed to %s", err.Error())
+	}
+
+	return *g
+}
+
+// Builds parser for grammar from it's BNF text, fails test on error
+func MustParser(t *testing.T, bnfText string) parser.LL1Parser {
+	t.Helper()
+
+	table, names, err := tablegen.FromGrammar(MustGrammar(t, bnfText))
+	if err != nil {
+		t.Fatalf("Failed to build parser table: %s", err.Error())
+	}
+
+	return parser.NewLL1Parser(*table, *names)
+}
+
 func IsMapsIntStringEqual(a map[int]string,
 	                      b map[int]string,
 	                      ) (adiff map[int]string,
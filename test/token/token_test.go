@@ -0,0 +1,101 @@
+package token_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/TooManySugar/ll1parser/pkg/parser"
+	"github.com/TooManySugar/ll1parser/pkg/token"
+	tc "github.com/TooManySugar/ll1parser/test/testcommon"
+)
+
+func TestFileSetPosition(t *testing.T) {
+
+	fset := token.NewFileSet()
+	a := fset.AddFile("a.txt", []byte("ab\ncd"))
+	b := fset.AddFile("b.txt", []byte("x\n\té𝄞z\n"))
+	empty := fset.AddFile("", nil)
+
+	position := func(name string, offset int,
+	                 line int, col int, col16 int) token.Position {
+		return token.Position{
+			Filename: name,
+			Offset: offset,
+			Line: line,
+			Column: col,
+			Column16: col16,
+		}
+	}
+
+	tests := []struct {
+		pos int
+		ref token.Position
+		str string
+	}{
+		{a.Pos(0), position("a.txt", 0, 1, 1, 1), "a.txt:1:1"},
+		{a.Pos(3), position("a.txt", 3, 2, 1, 1), "a.txt:2:1"},
+		{a.Pos(5), position("a.txt", 5, 2, 3, 3), "a.txt:2:3"},
+		{b.Pos(0), position("b.txt", 0, 1, 1, 1), "b.txt:1:1"},
+		// after tab, 2 byte é and 4 byte 𝄞
+		{b.Pos(9), position("b.txt", 9, 2, 8, 5), "b.txt:2:8"},
+		{b.Pos(11), position("b.txt", 11, 3, 1, 1), "b.txt:3:1"},
+		{empty.Pos(0), position("", 0, 1, 1, 1), "1:1"},
+		{empty.Pos(1), token.Position{}, "-"},
+		{-1, token.Position{}, "-"},
+	}
+
+	for _, test := range tests {
+		p := fset.Position(test.pos)
+		if p != test.ref {
+			t.Errorf("Position of %d: expected %+v, got %+v",
+			         test.pos, test.ref, p)
+		}
+		if p.String() != test.str {
+			t.Errorf("Position of %d: expected %s, got %s",
+			         test.pos, test.str, p)
+		}
+	}
+
+	if fset.File(b.Pos(11)) != b || fset.File(a.Pos(5)) != a {
+		t.Errorf("Files must own positions up to and including their end")
+	}
+}
+
+func TestFileSetParseErrors(t *testing.T) {
+
+	p := tc.MustParser(t, `<pair> ::= <item> "," <item>` + "\n" +
+	                      `<item> ::= "a" | "\n" | ""`)
+
+	fset := token.NewFileSet()
+	srcs := map[string]string{
+		"first.txt": "a,a",
+		"second.txt": "a,\n\n",
+	}
+
+	var refs = map[string]string{
+		"first.txt": "",
+		"second.txt": "second.txt:2:1",
+	}
+
+	for _, name := range []string{"first.txt", "second.txt"} {
+		f := fset.AddFile(name, []byte(srcs[name]))
+
+		_, _, err := p.Parse(srcs[name])
+		if refs[name] == "" {
+			if err != nil {
+				t.Errorf("Failed to parse %s: %s", name, err.Error())
+			}
+			continue
+		}
+
+		var se *parser.SyntaxError
+		if !errors.As(err, &se) {
+			t.Fatalf("Expected syntax error in %s, got %v", name, err)
+		}
+
+		pos := fset.Position(f.Pos(se.Offset)).String()
+		if pos != refs[name] {
+			t.Errorf("Expected error at %s, got %s", refs[name], pos)
+		}
+	}
+}